@@ -0,0 +1,47 @@
+/*
+ * @Time   : 2026/10/15 下午17:00
+ * @Email  : jjonline@jjonline.cn
+ */
+package queue
+
+import (
+	"math"
+	"time"
+)
+
+// BackoffStrategy 重试间隔策略：job执行失败可重试时计算本次放回队列的延迟时长
+type BackoffStrategy interface {
+	// NextDelay 计算重试延迟时长
+	// @param attempts 已尝试执行的次数，首次执行失败时为1
+	// @param base     任务类设置的重试间隔，见 TaskIFace.RetryInterval
+	NextDelay(attempts int64, base time.Duration) time.Duration
+}
+
+// LinearBackoff 固定间隔重试策略：每次均使用任务类设置的重试间隔（默认策略）
+type LinearBackoff struct{}
+
+// NextDelay implement BackoffStrategy
+func (LinearBackoff) NextDelay(attempts int64, base time.Duration) time.Duration {
+	return base
+}
+
+// ExponentialBackoff 指数退避重试策略：重试间隔为 base * 2^(attempts-1)，即首次重试间隔为base，之后逐次翻倍
+type ExponentialBackoff struct {
+	Max time.Duration // 重试间隔上限，小于等于0表示不限制
+}
+
+// NextDelay implement BackoffStrategy
+func (b ExponentialBackoff) NextDelay(attempts int64, base time.Duration) time.Duration {
+	delay := base
+	for i := int64(1); i < attempts && delay > 0; i++ {
+		// 翻倍溢出或超过上限即停止
+		if delay > math.MaxInt64/2 || (b.Max > 0 && delay >= b.Max) {
+			break
+		}
+		delay *= 2
+	}
+	if b.Max > 0 && delay > b.Max {
+		delay = b.Max
+	}
+	return delay
+}
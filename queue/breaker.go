@@ -0,0 +1,119 @@
+/*
+ * @Time   : 2026/10/16 下午8:00
+ * @Email  : jjonline@jjonline.cn
+ */
+package queue
+
+import (
+	"sync"
+	"time"
+)
+
+// 熔断器状态
+const (
+	breakerClosed   = iota // 关闭：正常取出
+	breakerOpen            // 熔断：冷却时长内不取出
+	breakerHalfOpen        // 半开：仅取出一批job试探
+)
+
+// circuitBreaker 任务类熔断器
+type circuitBreaker struct {
+	lock      sync.Mutex
+	threshold int           // 熔断的连续失败次数
+	cooldown  time.Duration // 熔断冷却时长
+	state     int           // 熔断器状态
+	failures  int           // 连续失败次数
+	changedAt time.Time     // 进入熔断或半开状态的时刻
+}
+
+// newCircuitBreaker 按任务类设置创建熔断器，未实现 CircuitBreakerTask 或连续失败次数小于等于0时返回nil
+func newCircuitBreaker(task TaskIFace) *circuitBreaker {
+	breakerTask, ok := task.(CircuitBreakerTask)
+	if !ok {
+		return nil
+	}
+	threshold, cooldown := breakerTask.CircuitBreaker()
+	if threshold <= 0 {
+		return nil
+	}
+	return &circuitBreaker{threshold: threshold, cooldown: cooldown}
+}
+
+// allow 检查当前是否可取出job：冷却结束后转为半开并放行一次试探，试探结果迟迟未到时每隔冷却时长再放行一次
+func (b *circuitBreaker) allow(now time.Time) (allowed bool, halfOpened bool) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	switch b.state {
+	case breakerOpen, breakerHalfOpen:
+		if now.Sub(b.changedAt) < b.cooldown {
+			return false, false
+		}
+		halfOpened = b.state == breakerOpen
+		b.state = breakerHalfOpen
+		b.changedAt = now
+		return true, halfOpened
+	}
+	return true, false
+}
+
+// record 记录一次执行结果，返回熔断器状态是否因此变为熔断或恢复
+func (b *circuitBreaker) record(success bool, now time.Time) (opened bool, closed bool) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if success {
+		closed = b.state != breakerClosed
+		b.state = breakerClosed
+		b.failures = 0
+		return false, closed
+	}
+
+	b.failures++
+	if b.state == breakerHalfOpen || (b.state == breakerClosed && b.failures >= b.threshold) {
+		b.state = breakerOpen
+		b.changedAt = now
+		return true, false
+	}
+	return false, false
+}
+
+// taskBreaker 获取任务类的熔断器，未启用熔断时返回nil
+func (m *manager) taskBreaker(task TaskIFace) *circuitBreaker {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.breakers[task.Name()]
+}
+
+// breakerAllow 检查任务类是否未熔断可取出job
+func (m *manager) breakerAllow(task TaskIFace) bool {
+	breaker := m.taskBreaker(task)
+	if breaker == nil {
+		return true
+	}
+	allowed, halfOpened := breaker.allow(time.Now())
+	if halfOpened {
+		m.looperLogger.Info("queue.breaker.half.open", field("queue", task.Name()))
+	}
+	return allowed
+}
+
+// breakerRecord 记录任务类一次执行结果，熔断或恢复时记录日志
+func (m *manager) breakerRecord(task TaskIFace, err error) {
+	breaker := m.taskBreaker(task)
+	if breaker == nil {
+		return
+	}
+	opened, closed := breaker.record(err == nil, time.Now())
+	if opened {
+		m.workerLogger.Warn(
+			"queue.breaker.opened",
+			field("queue", task.Name()),
+			field("cooldown", breaker.cooldown),
+			field("error", err),
+		)
+	}
+	if closed {
+		m.workerLogger.Info("queue.breaker.closed", field("queue", task.Name()))
+	}
+}
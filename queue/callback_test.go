@@ -0,0 +1,69 @@
+/*
+ * @Time   : 2026/10/19 上午11:00
+ * @Email  : jjonline@jjonline.cn
+ */
+package queue
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestOnQueueEmptyPanicRecovered 队列变为空的回调panic被捕获，looper继续取出job
+func TestOnQueueEmptyPanicRecovered(t *testing.T) {
+	task := &countTask{name: "queue_empty_panic"}
+	q := newTestQueue(t, 1, task)
+	var emptied int64
+	q.OnQueueEmpty(func(name string) {
+		atomic.AddInt64(&emptied, 1)
+		panic("queue empty callback panic")
+	})
+	if err := q.Dispatch(task, "first"); err != nil {
+		t.Fatalf("dispatch: %v", err)
+	}
+	if err := q.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer q.ShutDown(context.Background())
+
+	waitFor(t, 5*time.Second, func() bool { return atomic.LoadInt64(&emptied) == 1 })
+	if err := q.Dispatch(task, "second"); err != nil {
+		t.Fatalf("dispatch: %v", err)
+	}
+	waitFor(t, 5*time.Second, func() bool { return task.count() == 2 && atomic.LoadInt64(&emptied) == 2 })
+}
+
+// TestOnJobOutcomePanicRecovered job处理结果回调panic被捕获，任务类panic后的失败处理流程中也不会导致worker崩溃
+func TestOnJobOutcomePanicRecovered(t *testing.T) {
+	task := &countTask{
+		name:     "job_outcome_panic",
+		maxTries: 1,
+		handler: func(ctx context.Context, job *RawBody) error {
+			if job.String() == "panic" {
+				panic("task panic")
+			}
+			return nil
+		},
+	}
+	q := newTestQueue(t, 1, task)
+	var outcomes int64
+	q.OnJobOutcome(func(job JobIFace, outcome JobOutcome, detail JobOutcomeDetail) {
+		atomic.AddInt64(&outcomes, 1)
+		panic("job outcome callback panic")
+	})
+	if err := q.Dispatch(task, "panic"); err != nil {
+		t.Fatalf("dispatch: %v", err)
+	}
+	if err := q.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer q.ShutDown(context.Background())
+
+	waitFor(t, 5*time.Second, func() bool { return atomic.LoadInt64(&outcomes) == 1 })
+	if err := q.Dispatch(task, "ok"); err != nil {
+		t.Fatalf("dispatch: %v", err)
+	}
+	waitFor(t, 5*time.Second, func() bool { return task.count() == 2 && atomic.LoadInt64(&outcomes) == 2 })
+}
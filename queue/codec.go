@@ -0,0 +1,86 @@
+/*
+ * @Time   : 2026/10/16 上午11:00
+ * @Email  : jjonline@jjonline.cn
+ */
+package queue
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+)
+
+// GzipCodec gzip压缩任务参数的编解码器
+type GzipCodec struct {
+	Level int // 压缩级别，0使用 gzip.DefaultCompression
+}
+
+// Name implement Codec
+func (c GzipCodec) Name() string {
+	return "gzip"
+}
+
+// Encode implement Codec
+func (c GzipCodec) Encode(data []byte) ([]byte, error) {
+	level := c.Level
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+
+	var buf bytes.Buffer
+	writer, err := gzip.NewWriterLevel(&buf, level)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = writer.Write(data); err != nil {
+		return nil, err
+	}
+	if err = writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Decode implement Codec
+func (c GzipCodec) Decode(data []byte) ([]byte, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return ioutil.ReadAll(reader)
+}
+
+// encodeBody 按编解码器编码任务参数，未设置编解码器或参数小于最小编码长度时不编码
+// @param codec   编解码器，可为nil
+// @param minSize 最小编码长度
+// @param body    任务参数字面量
+func encodeBody(codec Codec, minSize int, body []byte) (encoded []byte, encoding string, err error) {
+	if codec == nil || len(body) < minSize {
+		return body, "", nil
+	}
+	if encoded, err = codec.Encode(body); err != nil {
+		return nil, "", err
+	}
+	return encoded, codec.Name(), nil
+}
+
+// rawBody 获取job解码后的任务参数，job参数未编码时原样返回
+func (m *manager) rawBody(job JobIFace) (*RawBody, error) {
+	payload := job.Payload()
+	if payload.Encoding == "" {
+		return payload.RawBody(), nil
+	}
+	if m.codec == nil || m.codec.Name() != payload.Encoding {
+		return nil, fmt.Errorf("queue payload encoding %s not supported", payload.Encoding)
+	}
+
+	decoded, err := m.codec.Decode(payload.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("queue payload decode failed: %s", err.Error())
+	}
+	body := payload.RawBody()
+	body.payload = decoded
+	return body, nil
+}
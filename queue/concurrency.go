@@ -0,0 +1,44 @@
+/*
+ * @Time   : 2026/10/15 上午10:20
+ * @Email  : jjonline@jjonline.cn
+ */
+package queue
+
+import "runtime"
+
+const (
+	// CPUBoundConcurrencyFactor 计算密集型任务的并发系数
+	// 任务执行期间基本占满CPU，并发数超过可用核数只会增加调度切换，故取1倍
+	CPUBoundConcurrencyFactor = 1
+	// IOBoundConcurrencyFactor IO密集型任务的并发系数
+	// 任务大部分时间阻塞在网络、磁盘等IO上，goroutine阻塞时不占用P，
+	// 需数倍于核数的并发才能把CPU用起来；队列任务多为请求接口、读写数据库，AutoConcurrency 默认按此系数计算
+	IOBoundConcurrencyFactor = 4
+
+	maxAutoConcurrency = 256 // 自动计算的并发数上限，避免大核数机器上瞬间打满下游连接池
+)
+
+// AutoConcurrency 按可用CPU数计算的默认并发消费数，适用于IO密集型任务
+//   - 取 runtime.GOMAXPROCS(0) 而非 runtime.NumCPU()：容器内限制了CPU配额或手动设置过GOMAXPROCS时以实际可调度的核数为准
+//   - New 时传入的 concurrent 小于等于0则使用该值
+func AutoConcurrency() int64 {
+	return AutoConcurrencyWithFactor(IOBoundConcurrencyFactor)
+}
+
+// AutoConcurrencyWithFactor 按可用CPU数乘以指定系数计算并发消费数
+//   - factor 小于等于0视为1，计算密集型任务可传 CPUBoundConcurrencyFactor
+//   - 结果最小为1，最大不超过256
+func AutoConcurrencyWithFactor(factor int) int64 {
+	if factor <= 0 {
+		factor = CPUBoundConcurrencyFactor
+	}
+
+	concurrent := int64(runtime.GOMAXPROCS(0)) * int64(factor)
+	if concurrent < 1 {
+		concurrent = 1
+	}
+	if concurrent > maxAutoConcurrency {
+		concurrent = maxAutoConcurrency
+	}
+	return concurrent
+}
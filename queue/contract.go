@@ -19,6 +19,7 @@ const (
 	shutdownPollIntervalMax   = 500 * time.Millisecond // 优雅关闭进程最大重复尝试间隔时长
 	DefaultMaxExecuteDuration = 900 * time.Second      // job任务执行时长极限预警值：15分钟
 	DefaultMaxTries           = 1                      // 默认最大重试次数：1次<即不重试>
+	UnlimitedTries            = 0                      // 最大尝试次数为0表示无限重试：执行失败始终放回重试，永不最终失败
 	DefaultRetryInterval      = 60                     // 默认下次任务重试间隔：1分钟<即可多次执行任务失败后下一次尝试是在60秒后>
 )
 
@@ -29,8 +30,50 @@ var (
 	ErrMaxAttemptsExceeded = errors.New("queue.max.execute.attempts")
 	// ErrAbortForWaitingPrevJobFinish 等待上一次任务执行结束退出
 	ErrAbortForWaitingPrevJobFinish = errors.New("queue.abort.for.waiting.prev.job.finish")
+	// ErrQueueStarted 队列已启动错误
+	ErrQueueStarted = errors.New("queue.error.queue.started")
+	// ErrShutdownGraceExceeded 优雅关闭时执行中的job均已超过其任务类允许的等待时长
+	ErrShutdownGraceExceeded = errors.New("queue.shutdown.grace.exceeded")
+	// ErrPartitionAtCapacity 分区执行中的job数已达上限
+	ErrPartitionAtCapacity = errors.New("queue.partition.at.capacity")
+	// ErrExecuteLimitReached 全局执行并发数已达上限
+	ErrExecuteLimitReached = errors.New("queue.execute.limit.reached")
+	// ErrNoTasksRegistered 启动时没有任何已注册的任务类
+	ErrNoTasksRegistered = errors.New("queue.error.no.tasks.registered")
+	// ErrJobTimeout job执行超过超时时长：执行结果为超时的job以该错误进入重试或最终失败流程
+	ErrJobTimeout = errors.New("queue.job.execute.timeout")
+	// ErrDuplicateJob 唯一job在唯一窗口期内重复投递，本次投递被丢弃
+	ErrDuplicateJob = errors.New("queue.job.duplicate")
+	// ErrTaskNotRegistered 取出的job找不到对应的已注册任务类
+	ErrTaskNotRegistered = errors.New("queue.task.not.registered")
+	// ErrPayloadInvalid job参数未通过任务类校验或无法解码为 TypedTask 的参数类型：该job不执行、不重试，直接按最终失败处理
+	ErrPayloadInvalid = errors.New("queue.job.payload.invalid")
+	// ErrJobExpired job自首次投递起已超过任务类设置的最长有效时长，不再执行或重试，见 MaxAgeTask
+	ErrJobExpired = errors.New("queue.job.expired")
+	// ErrJobCanceled 执行中的job被手动取消，不再重试，见 Queue.Cancel
+	ErrJobCanceled = errors.New("queue.job.canceled")
+	// ErrJobRecentlyCompleted job在去重窗口内已执行成功过，被再次取出时直接丢弃，见 Queue.SetCompletedDedup
+	ErrJobRecentlyCompleted = errors.New("queue.job.recently.completed")
 )
 
+// RescheduleError 任务类 Execute 返回该错误表示job暂不满足执行条件（例如依赖尚未就绪），延迟After后再次执行
+//  - 不视为执行失败：不消耗尝试次数，不触发重试、失败相关的回调与指标
+//  - 通过 Reschedule 构造，被包装后返回同样生效；任务类需自行避免无限期的稍后再执行
+type RescheduleError struct {
+	After time.Duration // 再次执行的延迟时长，底层驱动延迟精度为秒
+}
+
+// Error implement error
+func (e *RescheduleError) Error() string {
+	return "queue.job.reschedule after " + e.After.String()
+}
+
+// Reschedule 构造任务类要求稍后再执行的错误，见 RescheduleError
+// @param after 再次执行的延迟时长
+func Reschedule(after time.Duration) error {
+	return &RescheduleError{After: after}
+}
+
 // 任务输出相关文案变量统一定义：便于日志追踪
 var (
 	textJobProcessing = "queue.job.processing"   // job开始执行标记文案
@@ -38,6 +81,10 @@ var (
 	textJobFailed     = "queue.job.failed"       // job已执行失败标记文案<任务类返回了error>
 	textJobTooLong    = "queue.execute.too.long" // job多次尝试执行检查距离上次执行时间差已经大于设置的最大执行时长
 	textJobFailedLog  = "queue.failed.log"       // job执行失败标记文案
+	textJobSkipped    = "queue.job.skipped"      // job经任务类判断无需执行而跳过标记文案
+	textJobRequeued   = "queue.job.requeued"     // job在强制关闭时已放回队列标记文案
+	textJobInvalid    = "queue.job.invalid"      // job参数未通过任务类校验标记文案
+	textJobDeferred   = "queue.job.rescheduled"  // job经任务类要求稍后再执行标记文案
 )
 
 // region queue队列抽象
@@ -71,6 +118,83 @@ type QueueIFace interface {
 	GetConnection() (connection interface{}, err error)
 }
 
+// ReservedRequeuer 可选实现的队列契约：支持将保留（执行中）的job重新放回队列等待执行
+type ReservedRequeuer interface {
+	// RequeueReserved 将指定队列保留中的job重新放回队列，返回实际放回的job数量
+	// @param queue 队列的名称
+	// @param skip  返回true的jobID跳过不处理
+	RequeueReserved(queue string, skip func(id string) bool) (count int, err error)
+}
+
+// Peeker 可选实现的队列契约：查看待执行的job而不取出
+type Peeker interface {
+	// Peek 按执行顺序获取指定队列最多n个待执行job的payload，不影响job的保留状态，不含延迟中、保留中的job
+	// @param queue 队列的名称
+	// @param n     最多获取的job数量
+	Peek(queue string, n int) (payloads []Payload, err error)
+}
+
+// PriorityPopper 可选实现的队列契约：按优先级从高到低取出job，见 PriorityTask
+// 同一队列的各优先级job分别存储在独立的优先级子队列中，未实现时消费端按优先级从高到低逐个子队列调用 Pop
+type PriorityPopper interface {
+	// PopPriority 从优先级最高的非空子队列取出1条job
+	// @param queue  队列的名称
+	// @param levels 优先级级数，优先级取值范围为 [0, levels)
+	PopPriority(queue string, levels int) (job JobIFace, exist bool)
+}
+
+// QueueSizer 可选实现的队列契约：按状态分别统计队列中的job数量
+type QueueSizer interface {
+	// QueueSize 获取指定队列待执行、延迟中、保留（执行中）的job数量
+	// @param queue 队列的名称
+	QueueSize(queue string) (size QueueSize, err error)
+}
+
+// BatchPopper 可选实现的队列契约：支持一次取出多条job，减少与底层存储的网络往返
+// 未实现时消费端循环调用 Pop 逐条取出
+type BatchPopper interface {
+	// PopBatch 从队列取出最多n条job，队列为空时返回空切片与nil
+	// 部分取出失败时同时返回已取出的job与error，已取出的job仍需正常执行
+	// @param queue 队列的名称
+	// @param n     最多取出的job数量
+	PopBatch(queue string, n int) (jobs []JobIFace, err error)
+}
+
+// UniquePusher 可选实现的队列契约：支持唯一job投递
+type UniquePusher interface {
+	// PushUnique 唯一窗口期内同一唯一键仅投递一次，返回是否实际投递
+	// @param queue   队列的名称
+	// @param key     job唯一键
+	// @param payload 队列job载体
+	// @param ttl     唯一窗口期时长，自首次投递起计算
+	PushUnique(queue string, key string, payload interface{}, ttl time.Duration) (pushed bool, err error)
+}
+
+// DelayedPromoter 可选实现的队列契约：延迟任务需由消费端定期晋升到待执行队列
+// 底层存储无原生有序集合等延迟能力的队列实现实现该契约后，消费者启动时开启晋升协程按间隔定期调用
+// 取出job时即可处理到期延迟任务的队列实现（例如redis、memory驱动）无需实现，不会额外开启晋升协程
+type DelayedPromoter interface {
+	// PromoteDelayed 将指定队列中执行时刻已到的延迟任务移动到待执行队列，返回实际移动的job数量
+	// @param queue 队列的名称
+	PromoteDelayed(queue string) (count int, err error)
+}
+
+// backendClockAware 支持使用底层驱动时钟的队列实现
+// 生产者、消费者所在机器时钟存在偏差时，使用底层驱动统一时钟计算延迟任务的执行时刻
+type backendClockAware interface {
+	setBackendClock(enable bool)
+}
+
+// clockAware 支持替换时钟的队列实现，用于测试中控制延迟任务、重试间隔的时间流逝
+type clockAware interface {
+	setClock(clock func() time.Time)
+}
+
+// payloadCodecAware 支持替换job序列化格式的队列实现，不支持的序列化格式返回error
+type payloadCodecAware interface {
+	setPayloadCodec(codec PayloadCodec) error
+}
+
 // endregion
 
 // region job任务抽象
@@ -82,7 +206,7 @@ type JobIFace interface {
 	IsDeleted() (deleted bool)       // 检查任务是否已删除
 	IsReleased() (released bool)     // 检查任务是否已释放
 	Attempts() (attempt int64)       // 获取任务已尝试执行过的次数
-	PopTime() (time time.Time)       // 获取任务首次被pop取出的时刻
+	PopTime() (time time.Time)       // 获取任务本次被pop取出的时刻（重试时为最近一次取出时刻，首次取出时刻见 Payload().PopTime）
 	Timeout() (time time.Duration)   // 任务超时时长
 	TimeoutAt() (time time.Time)     // 任务执行超时的时刻
 	HasFailed() (hasFail bool)       // 检测当前job任务执行是否出现了错误
@@ -100,9 +224,29 @@ type JobIFace interface {
 // RawBody 队列execute执行时传递给执行方法的参数Raw结构：job任务参数的包装器
 //  - ID 内部标记队列任务的唯一ID，使用UUID生成
 type RawBody struct {
-	queue   string // 队列名
-	payload []byte // 调度队列塞入的数据体
-	ID      string // 队列内部唯一标识符ID
+	queue   string            // 队列名
+	payload []byte            // 调度队列塞入的数据体
+	headers map[string]string // 投递时携带的元数据头
+	ID      string            // 队列内部唯一标识符ID
+}
+
+// Queue 任务所属队列名称
+func (rawBody *RawBody) Queue() string {
+	return rawBody.queue
+}
+
+// Header 获取投递时携带的元数据头，不存在时返回空字符串
+func (rawBody *RawBody) Header(key string) string {
+	return rawBody.headers[key]
+}
+
+// Headers 获取投递时携带的全部元数据头，返回值为拷贝
+func (rawBody *RawBody) Headers() map[string]string {
+	headers := make(map[string]string, len(rawBody.headers))
+	for key, value := range rawBody.headers {
+		headers[key] = value
+	}
+	return headers
 }
 
 // Int 任务参数数据转int
@@ -145,40 +289,562 @@ func (rawBody *RawBody) Unmarshal(result interface{}) error {
 
 // Payload 存储于队列中的job任务结构
 type Payload struct {
-	Name          string `json:"Name"`          // 队列名称
-	ID            string `json:"ID"`            // 任务ID
-	MaxTries      int64  `json:"MaxTries"`      // 任务最大尝试次数，默认1
-	RetryInterval int64  `json:"RetryInterval"` // 当任务最大允许尝试次数大于0时，下次尝试之前的间隔时长，单位：秒
-	Attempts      int64  `json:"Attempts"`      // 任务已被尝试执行的的次数
-	Payload       []byte `json:"Payload"`       // 任务参数比特字面量，可decode成具体job被execute时的类型
-	PopTime       int64  `json:"PopTime"`       // 任务首次被取出执行的时间戳，取出的时候才去设置
-	Timeout       int64  `json:"Timeout"`       // 任务最大执行超时时长，单位：秒
-	TimeoutAt     int64  `json:"TimeoutAt"`     // 任务超时时刻时间戳，被执行时刻才会去设置
+	Name          string            `json:"Name"`                  // 队列名称
+	ID            string            `json:"ID"`                    // 任务ID
+	MaxTries      int64             `json:"MaxTries"`              // 任务最大尝试次数，默认1，0表示无限重试
+	RetryInterval int64             `json:"RetryInterval"`         // 当任务最大允许尝试次数大于0时，下次尝试之前的间隔时长，单位：秒
+	Attempts      int64             `json:"Attempts"`              // 任务已被尝试执行的的次数
+	Payload       []byte            `json:"Payload"`               // 任务参数比特字面量，可decode成具体job被execute时的类型
+	PopTime       int64             `json:"PopTime"`               // 任务首次被取出执行的时间戳，取出的时候才去设置
+	Timeout       int64             `json:"Timeout"`               // 任务最大执行超时时长，单位：秒
+	TimeoutAt     int64             `json:"TimeoutAt"`             // 任务超时时刻时间戳，被执行时刻才会去设置
+	Headers       map[string]string `json:"Headers,omitempty"`     // 投递时携带的元数据头，用于透传链路追踪上下文等信息
+	AvailableAt   int64             `json:"AvailableAt,omitempty"` // 任务计划可被执行的时刻时间戳（投递方本机时钟），投递时设置，用于计算投递延迟
+	Encoding      string            `json:"Encoding,omitempty"`    // 任务参数的编码名称，为空表示未编码，见 Codec
+	EnqueuedAt    int64             `json:"EnqueuedAt,omitempty"`  // 任务首次投递的时间戳（投递方本机时钟），放回重试、再次投递时保持不变，用于判断job是否过期
+	Priority      int               `json:"Priority,omitempty"`    // 任务优先级，0为普通优先级，见 PriorityTask
 }
 
 // RawBody PayLoad结构体获取载体实体
 func (payload *Payload) RawBody() *RawBody {
-	return &RawBody{queue: payload.Name, ID: payload.ID, payload: payload.Payload}
+	return &RawBody{queue: payload.Name, ID: payload.ID, payload: payload.Payload, headers: payload.Headers}
 }
 
+// Codec 任务参数编解码器，例如压缩较大的任务参数以节省底层存储空间
+// 仅编码任务参数字面量（即 Payload.Payload），job其他字段保持原样以便底层驱动读写
+type Codec interface {
+	// Name 编码名称，记录在 Payload.Encoding 中用于执行前选择解码方式，需唯一且保持不变
+	Name() string
+	// Encode 编码任务参数
+	Encode(data []byte) ([]byte, error)
+	// Decode 解码任务参数
+	Decode(data []byte) ([]byte, error)
+}
+
+// 内置支持的job序列化格式名称
+const (
+	PayloadFormatJSON    = "json"    // 默认格式，redis驱动使用cjson读写
+	PayloadFormatMsgpack = "msgpack" // redis驱动使用cmsgpack读写，需以字段名为键的map格式编码 Payload
+)
+
+// PayloadCodec job序列化器，负责 Payload 整体与底层存储字面量之间的转换，默认使用json
+// 与 Codec 不同，Codec 仅编码任务参数字面量，PayloadCodec 决定job在底层驱动中的存储格式
+type PayloadCodec interface {
+	// Name 序列化格式名称，底层驱动据此判断是否支持，见 PayloadFormatJSON 等常量
+	Name() string
+	// Marshal 序列化job
+	Marshal(payload Payload) ([]byte, error)
+	// Unmarshal 反序列化job
+	Unmarshal(data []byte, payload *Payload) error
+}
+
+// IDGenerator job ID生成方法，投递时调用，返回空字符串时使用默认的UUID
+// @param name 队列名称
+// @param body 任务参数序列化后的字面量
+type IDGenerator func(name string, body []byte) string
+
 // FailedJobHandler 失败任务记录|处理回调方法
 // @param *Payload 失败job的对象信息
 // @param error job任务失败的error报错信息
 type FailedJobHandler func(payload *Payload, err error) error
 
+// FailedRecordHandler 失败任务记录处理回调方法：接收任务类自定义的失败记录
+// @param name   失败job所属队列名称
+// @param record 失败记录，任务类实现了 FailureRecorder 则为其返回值，否则为失败job的 *Payload
+// @param err    job任务失败的error报错信息
+type FailedRecordHandler func(name string, record interface{}, err error) error
+
+// RunningJob 执行中的job快照信息
+type RunningJob struct {
+	ID       string    // 任务ID
+	Name     string    // 队列名称
+	WorkerID int64     // 执行该job的workerID
+	Attempts int64     // 当前为第几次尝试执行
+	PopTime  time.Time // 本次被取出执行的时刻
+}
+
+// TaskInfo 已注册任务类的配置信息
+type TaskInfo struct {
+	Name          string        // 队列名称
+	MaxTries      int64         // 最大尝试次数，0表示无限重试
+	RetryInterval int64         // 重试间隔，单位：秒
+	Timeout       time.Duration // 执行超时时长
+	Aliases       []string      // 任务类旧名称（别名），按名称排序
+}
+
+// QueueSize 队列各状态job数量
+type QueueSize struct {
+	Pending  int64 // 待执行job数
+	Delayed  int64 // 延迟中job数（含等待重试的job）
+	Reserved int64 // 保留中job数，即已取出执行中或执行超时待重新投递的job
+}
+
+// LatencyStats 队列最近执行成功job的执行时长分位数，自job取出时刻开始计算
+type LatencyStats struct {
+	Samples int           // 参与统计的样本数，最多为最近 LatencyWindowSize 个
+	P50     time.Duration // 50分位执行时长
+	P95     time.Duration // 95分位执行时长
+	P99     time.Duration // 99分位执行时长
+}
+
+// Stats 队列运行状态快照
+type Stats struct {
+	ActiveWorkers   int64         // 执行job中的worker数
+	IdleWorkers     int64         // 空闲等待job的worker数，未启动时为0
+	InWorkingCount  int64         // 当前实例执行中的job数
+	RegisteredTasks int64         // 已注册的任务类数量
+	ExecuteInFlight int64         // 占用全局执行并发名额的job数，见 Queue.SetExecuteLimit
+	ExecuteLimit    int64         // 全局执行并发数，0为不限制
+	LooperBusy      time.Duration // 启动以来所有looper累计取出、投递job以及等待空闲worker的时长
+	LooperIdle      time.Duration // 启动以来所有looper累计因所有队列均无job而休眠的时长
+	LooperIdleRatio float64       // looper空闲时长占比：持续偏高说明并发数过剩，持续偏低说明取出能力或worker不足
+	Paused          bool          // 是否暂停取出job
+	ShuttingDown    bool          // 是否处于优雅关闭中
+}
+
+// Config 队列生效中的配置快照，用于排查问题
+type Config struct {
+	Concurrent              int64             // 并发worker数
+	Loopers                 int               // looper协程数
+	PopBatchSize            int               // looper每次从单个队列取出的批数
+	PromoteInterval         time.Duration     // 延迟任务晋升扫描间隔，仅队列实现了 DelayedPromoter 时生效
+	DefaultTimeout          time.Duration     // job超时时长为0时使用的默认超时时长
+	MaxTimeout              time.Duration     // job超时时长上限，0为不限制
+	LooperJitterMin         time.Duration     // 所有队列均无job时looper休眠的最小间隔
+	LooperJitterMax         time.Duration     // 所有队列均无job时looper休眠的最大间隔
+	ChannelBuffer           int               // looper投递job到worker的通道缓冲大小，0为无缓冲即worker空闲时才投递
+	FastQueues              []string          // 快速队列名称，按名称排序
+	FastWorkers             int64             // 为快速队列预留的worker数
+	DedicatedWorkers        map[string]int64  // 设置了专用worker池的队列名称 => 专用worker数
+	ExecuteLimit            int64             // 全局执行并发数，0为不限制
+	RetryJitter             int               // job放回重试延迟的随机抖动百分比，0为不抖动
+	Tasks                   []string          // 已注册的任务类名称，按名称排序
+	Aliases                 map[string]string // 任务类旧名称 => 任务类名称
+	PartitionRedeliverDelay time.Duration     // 分区并发已达上限时job再次投递的延迟
+	FailedJobHandler        bool              // 是否设置了失败任务处理器
+	FailedRecordHandler     bool              // 是否设置了失败任务记录处理器
+	DuplicatePolicy         bool              // 是否设置了执行中重复job处理策略，否则再次投递
+	PanicNormalizer         bool              // 是否设置了panic值规范化方法，否则使用默认方法
+	PanicHandler            bool              // 是否设置了panic处理方法
+	ProgressSink            bool              // 是否设置了job执行进度接收方法
+	ResultStore             bool              // 是否设置了job执行结果存储
+	DeadLetterSuffix        string            // 死信队列名称后缀，为空表示未启用死信队列
+	ForceRequeueOnShutdown  bool              // 优雅关闭超时后是否将执行中的job放回队列
+	ShutdownQuietPeriod     time.Duration     // 优雅关闭时所有worker空闲后返回前的静默等待时长，0为不等待
+	UnregisteredDelay       time.Duration     // 找不到任务类的job再次投递的延迟
+	UnregisteredMaxRetries  int64             // 找不到任务类的job最多再次投递的次数，0为不限制
+	DriftThreshold          time.Duration     // job投递延迟告警阈值，0为不告警
+	ReserveTimeout          time.Duration     // 执行中重复job再次投递的延迟以及等待执行中job结束的最长时长，0为按job设置
+	CompletedDedupWindow    time.Duration     // 已执行成功job的去重窗口，0为不启用
+	StuckWindow             time.Duration     // 所有worker均在执行且状态无变化超过该时长视为卡死，0为不检查
+	Started                 bool              // 是否已启动
+	ShuttingDown            bool              // 是否处于优雅关闭中
+}
+
+// endregion
+
+// region 日志组件
+
+// LogComponent 队列内部日志组件名称，用于按组件设置不同的最低日志级别
+type LogComponent string
+
+const (
+	LogComponentLooper   LogComponent = "looper"   // looper循环调度组件
+	LogComponentWorker   LogComponent = "worker"   // worker工作进程组件（含job执行相关日志）
+	LogComponentShutdown LogComponent = "shutdown" // 优雅关闭组件
+)
+
+// endregion
+
+// region 执行中重复job处理策略
+
+// DuplicateAction 同一job仍在执行中时又被取出的处理动作
+type DuplicateAction int
+
+const (
+	DuplicateRedeliver DuplicateAction = iota // 作为延迟任务再次投递（默认行为）
+	DuplicateDrop                             // 直接丢弃：删除本次取出的job，避免重复执行
+	DuplicateWait                             // 阻塞等待执行中的job结束后再执行本次job，串行化避免并发重复执行
+)
+
+// DuplicatePolicy 执行中重复job处理策略：返回对本次取出job的处理动作
+// @param job 本次取出的job，其ID对应的job仍在执行中
+type DuplicatePolicy func(job JobIFace) DuplicateAction
+
+// endregion
+
+// region 投递语义
+
+// DeliveryMode job投递语义
+type DeliveryMode int
+
+const (
+	AtLeastOnce DeliveryMode = iota // 至少一次（默认）：执行失败、超时放回重试，执行中的job可能被再次取出，任务类需实现幂等
+	AtMostOnce                      // 至多一次：执行前即删除job，执行失败、超时、进程崩溃均不重试，job可能丢失但不会重复执行
+)
+
+// DeliveryModeTask 可选实现的任务类契约：设置job投递语义，适用于发送短信、扣款等无法实现幂等的任务
+//  - AtMostOnce 的job在调用 Execute 之前删除，执行失败、超时直接按最终失败处理，不消耗剩余尝试次数
+//  - 执行期间进程崩溃、强制关闭的job不会被再次取出或放回队列，即丢失该job，需权衡数据丢失与重复执行
+//  - 执行前的检查（ShouldRun 返回error、分区并发已满等）仍按原方式重试或再次投递，此时 Execute 尚未调用
+type DeliveryModeTask interface {
+	DeliveryMode() DeliveryMode
+}
+
+// endregion
+
+// region 全局执行并发限制
+
+// ExecuteLimitAction 全局执行并发数已达上限时对job的处理动作
+type ExecuteLimitAction int
+
+const (
+	ExecuteLimitWait      ExecuteLimitAction = iota // 占用worker阻塞等待执行名额（默认行为）
+	ExecuteLimitRedeliver                           // 稍后再次投递，不消耗尝试次数，释放worker执行其他job
+)
+
+// endregion
+
+// region job处理结果
+
+// JobOutcome manager对单次取出的job做出的处理决定
+type JobOutcome int
+
+const (
+	JobProcessed   JobOutcome = iota // 执行成功并删除
+	JobReleased                      // 放回队列稍后重试（消耗尝试次数），或关闭时未投递给worker而立即放回
+	JobFailed                        // 尝试次数耗尽最终失败并删除
+	JobRedelivered                   // 未执行而作为延迟任务再次投递（执行中重复job、分区并发已达上限），或任务类要求稍后再执行，见 Reschedule
+	JobDropped                       // 未执行而直接丢弃（执行中重复job的丢弃策略）
+	JobSkipped                       // 任务类判断无需执行而跳过并删除，见 ShouldRunTask
+)
+
+// String 处理结果的文本表示
+func (o JobOutcome) String() string {
+	switch o {
+	case JobProcessed:
+		return "processed"
+	case JobReleased:
+		return "released"
+	case JobFailed:
+		return "failed"
+	case JobRedelivered:
+		return "redelivered"
+	case JobDropped:
+		return "dropped"
+	case JobSkipped:
+		return "skipped"
+	default:
+		return "unknown"
+	}
+}
+
+// JobOutcomeDetail job处理结果详情
+type JobOutcomeDetail struct {
+	Delay time.Duration // 放回或再次投递的延迟时长
+	Err   error         // 导致该处理结果的错误，执行成功时为nil
+}
+
+// JobOutcomeHandler job处理结果回调
+type JobOutcomeHandler func(job JobIFace, outcome JobOutcome, detail JobOutcomeDetail)
+
+// endregion
+
+// region job状态变化事件
+
+// JobEventType job状态变化事件类型
+type JobEventType int
+
+const (
+	EventJobPopped    JobEventType = iota // job被worker接收，尚未检查是否可执行
+	EventJobStarted                       // 任务类开始执行
+	EventJobSucceeded                     // 任务类执行成功
+	EventJobFailed                        // 尝试次数耗尽等原因最终失败
+	EventJobRetried                       // 执行失败放回队列稍后重试
+	EventJobDeleted                       // job从队列中删除
+)
+
+// String 事件类型的文本表示
+func (t JobEventType) String() string {
+	switch t {
+	case EventJobPopped:
+		return "popped"
+	case EventJobStarted:
+		return "started"
+	case EventJobSucceeded:
+		return "succeeded"
+	case EventJobFailed:
+		return "failed"
+	case EventJobRetried:
+		return "retried"
+	case EventJobDeleted:
+		return "deleted"
+	default:
+		return "unknown"
+	}
+}
+
+// JobEvent job状态变化事件
+type JobEvent struct {
+	Type     JobEventType // 事件类型
+	ID       string       // job ID
+	Name     string       // 队列名称
+	WorkerID int64        // 执行job的workerID，无法确定时为-1
+	Attempts int64        // 事件发生时job已被尝试执行的次数
+	Err      error        // 导致失败、重试的错误，其他事件为nil
+	Time     time.Time    // 事件发生时刻
+}
+
+// endregion
+
+// region panic值规范化
+
+// PanicNormalizer 将任务类执行过程中panic的值规范化为error
+// 规范化后的error用于记录日志以及后续的重试、失败处理流程
+// @param recovered recover捕获到的panic值
+type PanicNormalizer func(recovered interface{}) error
+
+// PanicHandler 任务类执行panic时的处理方法，例如将panic及其调用栈上报到Sentry等错误追踪平台
+// 处理方法执行完毕后队列仍按执行失败进入重试或最终失败流程
+// @param job       发生panic的job
+// @param recovered recover捕获到的panic值
+// @param stack     发生panic时的调用栈
+type PanicHandler func(job JobIFace, recovered interface{}, stack []byte)
+
+// endregion
+
+// region job执行进度
+
+// Progress job执行进度报告器，实现了 ProgressAware 的任务类执行时获得
+type Progress interface {
+	// Report 报告当前执行进度
+	// @param percent 进度百分比，取值0至100，超出范围时截断
+	// @param msg     进度说明
+	Report(percent float64, msg string)
+}
+
+// ProgressSink job执行进度接收方法，通常按 job.Payload().ID 持久化进度供业务查询展示
+// 在执行job的协程中同步调用，耗时操作请自行异步处理
+// @param job     报告进度的job
+// @param percent 进度百分比
+// @param msg     进度说明
+type ProgressSink func(job JobIFace, percent float64, msg string)
+
+// endregion
+
+// region job执行结果存储
+
+// ResultStore job执行结果存储，保存实现了 ResultTask 的任务类执行成功的返回结果
+// 通常按jobID持久化到redis、数据库等，供web请求按jobID轮询异步计算的结果
+type ResultStore interface {
+	// Save 保存job执行结果，在执行job的协程中同步调用，保存失败仅记录日志
+	// @param jobID  job的ID，即 job.Payload().ID
+	// @param result 任务类返回的执行结果
+	Save(jobID string, result []byte) error
+}
+
+// endregion
+
+// region 执行中间件
+
+// ExecuteFunc 任务类执行方法签名，与 TaskIFace.Execute 一致
+type ExecuteFunc func(ctx context.Context, job *RawBody) error
+
+// Middleware 任务类执行中间件：包装执行方法实现日志、链路追踪、指标等横切逻辑
+// 中间件内需调用next继续执行，不调用则跳过任务类执行，返回的error按任务类执行结果处理
+type Middleware func(next ExecuteFunc) ExecuteFunc
+
 // endregion
 
 // region 任务类契约 && 任务类默认设置嵌入结构体
 
 // TaskIFace 定义队列Job任务执行逻辑的契约(队列任务执行类)
 type TaskIFace interface {
-	MaxTries() int64                                 // 定义队列任务最大尝试次数：任务执行的最大尝试次数
+	MaxTries() int64                                 // 定义队列任务最大尝试次数：任务执行的最大尝试次数，0表示无限重试
 	RetryInterval() int64                            // 定义队列任务最大尝试间隔：当任务执行失败后再次尝试执行的间隔时长，单位：秒
 	Timeout() time.Duration                          // 定义队列超时方法：返回超时时长
 	Name() string                                    // 定义队列名称方法：返回队列名称
 	Execute(ctx context.Context, job *RawBody) error // 定义队列任务执行时的方法：执行成功返回nil，执行失败返回error
 }
 
+// PriorityTask 可选实现的任务类契约：同一队列内按优先级从高到低执行job，例如紧急邮件优先于普通邮件发送
+//  - PriorityLevels 返回优先级级数，投递时通过 WithPriority 指定的优先级取值范围为 [0, levels)，超出范围按最近的边界值处理
+//  - 各优先级job分别存储在独立的优先级子队列中，0级即原队列；放回重试、再次投递的job保持原优先级
+//  - 仅在取出时优先取出高优先级job，已取出执行中的低优先级job不会被中断；高优先级job持续投递时低优先级job可能长时间得不到执行
+//  - 返回值小于等于1时等同于未实现；实现了 BatchTask 时批次内job同样按优先级取出
+type PriorityTask interface {
+	PriorityLevels() int
+}
+
+// BatchTask 可选实现的批次任务类契约：worker一次取出同一队列的多个job串行执行，用于摊薄单批次的连接建立等准备开销
+//  - BatchSize 返回单批次最多取出的job数量，小于等于1时等同于未实现
+//  - 批次中某个job执行失败按该job自身的重试设置处理，批次内剩余job继续执行
+//  - 批次内排在后面的job需等待前面的job执行完毕，批次过大可能导致后面的job超过超时时长被再次取出
+type BatchTask interface {
+	BatchSize() int64
+}
+
+// ShutdownGraceTask 可选实现的任务类契约：自定义优雅关闭时等待该任务类执行中job结束的最长时长
+//  - 未实现时使用任务类的超时时长 Timeout 作为等待时长
+//  - 执行中的job均超过各自的等待时长后优雅关闭不再等待，返回 ErrShutdownGraceExceeded
+//  - 优雅关闭传入的上下文仍为总的等待上限
+type ShutdownGraceTask interface {
+	ShutdownGrace() time.Duration
+}
+
+// FailureRecorder 可选实现的任务类契约：自定义失败任务的失败记录
+//  - 例如提取关键字段写入失败记录表，使失败记录结构由任务类自行维护
+//  - 返回值传递给 SetFailedRecordHandler 设置的失败任务记录处理器，FailedJobHandler 仍接收失败job的 *Payload
+//  - 死信队列仍存储失败job的完整payload，以便重放
+type FailureRecorder interface {
+	FailureRecord(payload Payload, err error) interface{}
+}
+
+// ConcurrencyTask 可选实现的任务类契约：限制该任务类在当前实例中的最大并发执行数
+//  - 返回值小于等于0表示不限制，仍受全局worker数量限制
+//  - 并发执行数已达上限时looper暂停从该队列取出job，不占用worker、不阻塞其他队列
+//  - 任务类旧名称（见 AliasTask）队列中的job与该任务类共用同一并发上限
+type ConcurrencyTask interface {
+	Concurrency() int64
+}
+
+// RateLimitTask 可选实现的任务类契约：限制该任务类每秒取出执行的job数量，与worker数量无关
+//  - 基于令牌桶实现，桶容量为每秒速率向上取整，即空闲后最多可连续取出1秒的配额
+//  - 超出速率的job留在队列中不被取出，不占用worker，不影响其他队列的执行
+//  - 限制范围为单个消费者进程，多实例部署时总速率为各实例速率之和
+//  - 返回值小于等于0时不限制
+type RateLimitTask interface {
+	RatePerSecond() float64
+}
+
+// CircuitBreakerTask 可选实现的任务类契约：熔断，下游依赖故障时暂停取出该任务类的job，避免持续失败重试冲击下游
+//  - 连续执行失败（含超时、panic）达到failures次后熔断，cooldown时长内不再取出该任务类的job
+//  - 冷却结束后进入半开状态，仅取出一批job试探：执行成功则恢复，失败则再次熔断
+//  - 熔断状态范围为单个消费者进程；failures小于等于0时不熔断
+type CircuitBreakerTask interface {
+	CircuitBreaker() (failures int, cooldown time.Duration)
+}
+
+// WeightedTask 可选实现的任务类契约：设置队列调度权重
+//  - looper每轮对每个队列至少取出1次，权重为n的队列每轮最多连续取出n次（每次取出一批，见 BatchTask）
+//  - 未实现或返回值小于等于1时权重为1，即各队列每轮均取出1次
+//  - 队列为空时本轮即停止取出，低流量队列每轮仍有取出机会不会饿死
+type WeightedTask interface {
+	Weight() int64
+}
+
+// PartitionTask 可选实现的任务类契约：按分区键控制同一分区的并发执行数
+//  - PartitionKey 从job参数中提取分区键，例如业务实体ID，同一分区的job默认同一时刻仅执行1个
+//  - 分区执行中的job数已达上限时，job稍后再次投递，不消耗尝试次数
+//  - 分区并发仅在当前消费者进程内控制，多个消费者进程之间不做协调
+type PartitionTask interface {
+	PartitionKey(job *RawBody) string
+}
+
+// PartitionConcurrencyTask 可选实现的任务类契约：配合 PartitionTask 设置单个分区最大并发执行数
+//  - 未实现或返回值小于等于1时单个分区同一时刻仅执行1个job
+type PartitionConcurrencyTask interface {
+	PartitionConcurrency() int64
+}
+
+// AliasTask 可选实现的任务类契约：任务类更名时声明仍需消费的旧队列名称
+//  - 投递始终使用任务类 Name 返回的新名称，旧名称仅用于消费
+//  - 旧名称队列中待执行、保留中以及稍后重试的job均由当前任务类执行，全部消费完毕后即可移除旧名称
+type AliasTask interface {
+	Aliases() []string
+}
+
+// MaxAgeTask 可选实现的任务类契约：job自首次投递起的最长有效时长，与最大尝试次数互补的按时间截止的重试窗口
+//  - 执行前检查，已超过有效时长的job即使仍有剩余尝试次数也不再执行，以 ErrJobExpired 按最终失败处理
+//  - 例如超过1小时的通知不再发送；返回值小于等于0时不限制
+//  - 按job首次投递时刻计算（见 Payload.EnqueuedAt），旧版本投递的不含该时刻的job不检查
+type MaxAgeTask interface {
+	MaxAge() time.Duration
+}
+
+// ValidatableTask 可选实现的任务类契约：执行前校验job参数，拦截生产者投递的格式错误的job
+//  - 返回error表示job参数无效，job不执行、不消耗剩余尝试次数，直接按最终失败处理
+//  - 最终失败的error包装了 ErrPayloadInvalid，可通过 errors.Is 与执行失败区分
+//  - 在 ShouldRunTask 之前调用，Execute 中无需再重复校验
+type ValidatableTask interface {
+	Validate(job *RawBody) error
+}
+
+// LogFieldsTask 可选实现的任务类契约：从job参数提取附加到该job执行过程所有日志的字段，例如租户ID
+//  - 执行、重试、超时、失败等与该job相关的日志均附加返回的字段，便于多租户场景按字段过滤日志
+//  - 每次执行job时调用一次，返回的字段用于本次执行的所有日志，需快速返回；job参数无法解码时不附加字段
+type LogFieldsTask interface {
+	LogFields(job *RawBody) []Field
+}
+
+// ShouldRunTask 可选实现的任务类契约：执行前由任务类根据job参数判断是否仍需执行
+//  - 返回false表示job已无需执行（例如目标数据已被删除），job直接删除且不视为失败、不重试
+//  - 返回error按本次执行失败处理，依据重试设置重试或最终失败
+type ShouldRunTask interface {
+	ShouldRun(job *RawBody) (bool, error)
+}
+
+// TimeoutTask 可选实现的任务类契约：job执行超时时的清理回调
+//  - 超时后 Execute 的ctx已取消，但任务类未响应ctx取消时 Execute 仍会继续执行，可在此释放资源、记录现场等
+//  - 回调在worker协程中同步执行，之后job以 ErrJobTimeout 进入重试或最终失败流程
+//  - 回调中的panic被捕获并记录日志，不影响worker继续执行
+type TimeoutTask interface {
+	OnTimeout(payload Payload)
+}
+
+// SuccessAware 可选实现的任务类契约：job执行成功并删除后的回调，用于发送事件、更新业务状态等后置处理
+//  - 回调panic被捕获并记录日志，不影响job已执行成功的结果
+type SuccessAware interface {
+	OnSuccess(payload Payload)
+}
+
+// FailureAware 可选实现的任务类契约：job尝试次数耗尽最终失败后的回调
+//  - 触发顺序：job删除并标记失败 -> OnFailure -> 队列级别的 FailedJobHandler、FailedRecordHandler
+//  - 执行失败但仍可重试的job不触发
+//  - 回调panic被捕获并记录日志，不影响后续失败处理器的执行
+type FailureAware interface {
+	OnFailure(payload Payload, err error)
+}
+
+// RetryAware 可选实现的任务类契约：job执行失败但仍可重试、放回队列后的回调
+//  - 与 FailureAware 互斥，用于区分可恢复的临时失败与最终失败，例如通知“重试中（3/5）”而非失败告警
+//  - attempt 为本次已尝试执行的次数，nextDelay 为本次放回队列的重试间隔
+//  - 回调panic被捕获并记录日志
+type RetryAware interface {
+	OnRetry(payload Payload, attempt int64, nextDelay time.Duration)
+}
+
+// DuplicateAware 可选实现的任务类契约：取出的job与执行中的job ID相同时的回调
+//  - 通常意味着执行中的job已超时仍未退出，可在此告警并据此调整超时时长
+//  - 回调在按 SetDuplicatePolicy 策略处理本次job之前同步执行，panic被捕获并记录日志
+type DuplicateAware interface {
+	OnDuplicateInFlight(payload Payload)
+}
+
+// ProgressAware 可选实现的任务类契约：执行时报告进度，适用于视频转码等长时间执行的任务
+//  - 实现后队列调用 ExecuteWithProgress 而非 Execute 执行job，Execute 仍需实现以满足 TaskIFace
+//  - 进度通过 SetProgressSink 设置的接收方法转发，未设置时报告的进度被丢弃
+type ProgressAware interface {
+	ExecuteWithProgress(ctx context.Context, job *RawBody, progress Progress) error
+}
+
+// ResultTask 可选实现的任务类契约：执行成功后返回结果，由 SetResultStore 设置的结果存储按jobID保存
+//  - 实现后队列调用 ExecuteResult 而非 Execute 执行job，Execute 仍需实现以满足 TaskIFace
+//  - 仅执行成功即返回nil error时保存结果，返回nil结果时不保存
+//  - 与 ProgressAware 同时实现时使用 ExecuteResult，不报告进度
+type ResultTask interface {
+	ExecuteResult(ctx context.Context, job *RawBody) ([]byte, error)
+}
+
+// ScheduledTask 可选实现的任务类契约：按cron表达式周期性的自动投递job
+//  - 表达式为标准5段式（分 时 日 月 周），也支持 @hourly、@every 5m 等描述符，注册时校验
+//  - 投递的job参数为计划投递时刻的秒级时间戳，可通过 RawBody.Int64 获取
+//  - 每个消费者进程均会投递，多实例部署时仅需在一个实例上注册周期任务
+//  - job的编码器、默认超时时长与 Queue 投递job的设置一致；启动后通过 Register 注册的周期任务同样按表达式投递
+type ScheduledTask interface {
+	Schedule() string
+}
+
 // DefaultTaskSetting 默认task设置struct：实现默认的最大尝试次数、尝试间隔时长、最大执行时长
 type DefaultTaskSetting struct{}
 
@@ -0,0 +1,47 @@
+/*
+ * @Time   : 2026/10/18 上午10:00
+ * @Email  : jjonline@jjonline.cn
+ */
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestDeadLetterResetsAttemptsAndDoesNotNest 死信job重置尝试次数，消费死信队列再次失败时不再投递到 name:dead:dead
+func TestDeadLetterResetsAttemptsAndDoesNotNest(t *testing.T) {
+	fail := func(ctx context.Context, job *RawBody) error { return errors.New("always fail") }
+	task := &countTask{name: "dead_letter", maxTries: 2, handler: fail}
+	replay := &countTask{name: "dead_letter:dead", maxTries: 2, handler: fail}
+	q := newTestQueue(t, 1, task)
+	q.SetDeadLetterQueue(":dead")
+	if err := q.Dispatch(task, "dead"); err != nil {
+		t.Fatalf("dispatch: %v", err)
+	}
+	if err := q.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer q.ShutDown(context.Background())
+
+	waitFor(t, 5*time.Second, func() bool { return q.queue.Size(replay.Name()) == 1 })
+	payloads, err := q.Peek(replay.Name(), 1)
+	if err != nil || len(payloads) != 1 {
+		t.Fatalf("peek: %v %v", payloads, err)
+	}
+	if payloads[0].Attempts != 0 {
+		t.Fatalf("expected dead letter attempts reset, got %d", payloads[0].Attempts)
+	}
+
+	// 注册死信队列任务类重放，再次最终失败后不再嵌套投递
+	if err = q.Register(replay); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	waitFor(t, 5*time.Second, func() bool { return replay.count() == 2 && q.queue.Size(replay.Name()) == 0 })
+	time.Sleep(100 * time.Millisecond)
+	if size := q.queue.Size(replay.Name() + ":dead"); size != 0 {
+		t.Fatalf("expected no nested dead letter queue, got %d", size)
+	}
+}
@@ -0,0 +1,75 @@
+/*
+ * @Time   : 2026/10/15 上午11:00
+ * @Email  : jjonline@jjonline.cn
+ */
+package queue
+
+import (
+	"time"
+)
+
+// DispatchOption 投递任务可选项
+type DispatchOption func(options *dispatchOptions)
+
+// dispatchOptions 投递任务可选项集合
+type dispatchOptions struct {
+	availableAt time.Time         // 任务可被执行的时刻，零值表示立即执行
+	uniqueKey   string            // job唯一键，空字符串表示非唯一job
+	uniqueFor   time.Duration     // 唯一窗口期时长
+	headers     map[string]string // job元数据头
+	priority    int               // job优先级，仅任务类实现了 PriorityTask 时生效
+}
+
+// newDispatchOptions 应用投递任务可选项
+func newDispatchOptions(opts []DispatchOption) *dispatchOptions {
+	options := &dispatchOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	return options
+}
+
+// WithAvailableAt 指定任务可被执行的时刻
+// 1、投递时按本机时钟换算为相对于当前时刻的延迟时长投递为延迟任务，开启 SetBackendClock 时执行时刻为底层驱动时钟的当前时刻加该延迟时长
+// 2、指定的时刻为过去时刻或当前时刻时作为普通任务立即执行
+func WithAvailableAt(t time.Time) DispatchOption {
+	return func(options *dispatchOptions) {
+		options.availableAt = t
+	}
+}
+
+// WithUniqueFor 投递唯一job：自首次投递起的唯一窗口期内，同一队列同一唯一键的job仅投递一次
+// 1、窗口期内重复投递的job被丢弃，投递方法返回 ErrDuplicateJob，上游至少一次投递的场景可按投递成功处理
+// 2、仅支持立即执行的job，不可与 WithAvailableAt 同时使用
+// 3、需队列底层驱动实现 UniquePusher，redis与memory驱动均已支持
+// @param key 唯一键，通常为业务主键等可标识同一逻辑job的值
+// @param d   唯一窗口期时长
+func WithUniqueFor(key string, d time.Duration) DispatchOption {
+	return func(options *dispatchOptions) {
+		options.uniqueKey = key
+		options.uniqueFor = d
+	}
+}
+
+// WithHeaders 投递job时携带元数据头，执行时通过 RawBody.Header 读取
+// 1、用于透传链路追踪上下文、请求ID等与任务参数无关的信息，可多次使用，同名键后者覆盖前者
+// 2、元数据头随job存储，放回重试、再次投递时保持不变
+func WithHeaders(headers map[string]string) DispatchOption {
+	return func(options *dispatchOptions) {
+		if options.headers == nil {
+			options.headers = make(map[string]string, len(headers))
+		}
+		for key, value := range headers {
+			options.headers[key] = value
+		}
+	}
+}
+
+// WithPriority 指定job优先级，同一队列内优先级高的job先被取出执行
+// 1、仅任务类实现了 PriorityTask 时生效，取值范围为 [0, PriorityLevels())，超出范围按最近的边界值处理
+// 2、默认0为最低优先级；放回重试、再次投递的job保持原优先级
+func WithPriority(priority int) DispatchOption {
+	return func(options *dispatchOptions) {
+		options.priority = priority
+	}
+}
@@ -0,0 +1,39 @@
+/*
+ * @Time   : 2026/10/18 下午16:00
+ * @Email  : jjonline@jjonline.cn
+ */
+package queue
+
+import (
+	"testing"
+	"time"
+)
+
+// TestDispatchByNameUsesRegisteredTask 按任务name投递的job设置取自已注册的任务类
+func TestDispatchByNameUsesRegisteredTask(t *testing.T) {
+	task := &countTask{name: "dispatch_name", maxTries: 3, retryInterval: 5}
+	q := newTestQueue(t, 1, task)
+
+	if err := q.manager.Dispatch("unregistered", "x"); err == nil {
+		t.Fatalf("expected error for unregistered task")
+	}
+	if err := q.manager.Dispatch(task.Name(), "now"); err != nil {
+		t.Fatalf("dispatch: %v", err)
+	}
+	if err := q.DispatchAfter(task.Name(), time.Hour, "later"); err != nil {
+		t.Fatalf("dispatch after: %v", err)
+	}
+
+	size, err := q.QueueSize(task.Name())
+	if err != nil || size.Pending != 1 || size.Delayed != 1 {
+		t.Fatalf("expected 1 pending and 1 delayed job, got %+v %v", size, err)
+	}
+	payloads, err := q.Peek(task.Name(), 1)
+	if err != nil || len(payloads) != 1 {
+		t.Fatalf("peek: %v %v", payloads, err)
+	}
+	payload := payloads[0]
+	if payload.ID == "" || payload.MaxTries != 3 || payload.RetryInterval != 5 || string(payload.Payload) != "now" {
+		t.Fatalf("unexpected payload %+v", payload)
+	}
+}
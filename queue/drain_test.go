@@ -0,0 +1,57 @@
+/*
+ * @Time   : 2026/10/17 上午11:00
+ * @Email  : jjonline@jjonline.cn
+ */
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/goleak"
+)
+
+// TestDrainProcessesAllJobs 投递100个job后 Drain 返回时所有job均已执行
+func TestDrainProcessesAllJobs(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	task := &countTask{name: "drain_all"}
+	q := newTestQueue(t, 8, task)
+	for i := 0; i < 100; i++ {
+		if err := q.Dispatch(task, i); err != nil {
+			t.Fatalf("dispatch: %v", err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := q.Drain(ctx); err != nil {
+		t.Fatalf("drain: %v", err)
+	}
+	if task.count() != 100 {
+		t.Fatalf("expected 100 jobs processed, got %d", task.count())
+	}
+}
+
+// TestDrainContextDoneStopsQueue ctx先结束时 Drain 仍停止队列并返回ctx的错误
+func TestDrainContextDoneStopsQueue(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	task := &countTask{name: "drain_ctx_done"}
+	q := newTestQueue(t, 1, task)
+	// 延迟job使队列始终无法排空
+	if err := q.Delay(task, "later", time.Hour); err != nil {
+		t.Fatalf("delay: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	if err := q.Drain(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if !q.IsShuttingDown() {
+		t.Fatal("queue should be shutting down after drain returned")
+	}
+}
@@ -0,0 +1,61 @@
+/*
+ * @Time   : 2026/10/19 上午10:00
+ * @Email  : jjonline@jjonline.cn
+ */
+package queue
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestSameIDJobsNeverRunConcurrently 同时取出的同ID job仅一个执行，另一个按重复job策略处理
+func TestSameIDJobsNeverRunConcurrently(t *testing.T) {
+	const pairs = 200
+	var (
+		lock       sync.Mutex
+		running    = make(map[string]int)
+		violations int64
+	)
+	task := &countTask{
+		name: "duplicate_race",
+		handler: func(ctx context.Context, job *RawBody) error {
+			lock.Lock()
+			running[job.ID]++
+			if running[job.ID] > 1 {
+				atomic.AddInt64(&violations, 1)
+			}
+			lock.Unlock()
+
+			time.Sleep(2 * time.Millisecond)
+
+			lock.Lock()
+			running[job.ID]--
+			lock.Unlock()
+			return nil
+		},
+	}
+	q := newTestQueue(t, 16, task)
+	q.SetIDGenerator(func(name string, body []byte) string { return "dup-" + string(body) })
+	q.SetDuplicatePolicy(func(job JobIFace) DuplicateAction { return DuplicateDrop })
+	for i := 0; i < pairs; i++ {
+		for copies := 0; copies < 2; copies++ {
+			if err := q.Dispatch(task, strconv.Itoa(i)); err != nil {
+				t.Fatalf("dispatch: %v", err)
+			}
+		}
+	}
+	if err := q.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer q.ShutDown(context.Background())
+
+	waitFor(t, 10*time.Second, func() bool { return task.count() >= pairs && q.manager.inWorkingCount() == 0 })
+	if n := atomic.LoadInt64(&violations); n != 0 {
+		t.Fatalf("same ID job executed concurrently %d times", n)
+	}
+}
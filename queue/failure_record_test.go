@@ -0,0 +1,82 @@
+/*
+ * @Time   : 2026/10/18 下午17:00
+ * @Email  : jjonline@jjonline.cn
+ */
+package queue
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordTask 测试用任务类：自定义失败记录
+type recordTask struct {
+	countTask
+}
+
+func (task *recordTask) FailureRecord(payload Payload, err error) interface{} {
+	return map[string]string{"id": payload.ID, "error": err.Error()}
+}
+
+// TestFailedRecordHandlerReceivesFailureRecord 失败任务记录处理器接收任务类自定义的失败记录，未实现时接收 *Payload
+// 失败任务处理器仍接收失败job的 *Payload
+func TestFailedRecordHandlerReceivesFailureRecord(t *testing.T) {
+	fail := func(ctx context.Context, job *RawBody) error { return errors.New("always fail") }
+	custom := &recordTask{countTask{name: "failure_record", maxTries: 1, handler: fail}}
+	plain := &countTask{name: "failure_payload", maxTries: 1, handler: fail}
+	q := newTestQueue(t, 1, custom, plain)
+
+	var (
+		lock     sync.Mutex
+		records  []interface{}
+		payloads []*Payload
+	)
+	q.SetFailedJobHandler(func(payload *Payload, err error) error {
+		lock.Lock()
+		defer lock.Unlock()
+		payloads = append(payloads, payload)
+		return nil
+	})
+	q.SetFailedRecordHandler(func(name string, record interface{}, err error) error {
+		lock.Lock()
+		defer lock.Unlock()
+		records = append(records, record)
+		return nil
+	})
+	if err := q.Dispatch(custom, "custom"); err != nil {
+		t.Fatalf("dispatch: %v", err)
+	}
+	if err := q.Dispatch(plain, "plain"); err != nil {
+		t.Fatalf("dispatch: %v", err)
+	}
+	if err := q.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer q.ShutDown(context.Background())
+
+	waitFor(t, 5*time.Second, func() bool {
+		lock.Lock()
+		defer lock.Unlock()
+		return len(records) == 2 && len(payloads) == 2
+	})
+
+	var customRecord, payloadRecord int
+	for _, record := range records {
+		switch value := record.(type) {
+		case map[string]string:
+			if value["error"] == "always fail" && value["id"] != "" {
+				customRecord++
+			}
+		case *Payload:
+			if value.Name == plain.Name() {
+				payloadRecord++
+			}
+		}
+	}
+	if customRecord != 1 || payloadRecord != 1 {
+		t.Fatalf("unexpected failure records %v", records)
+	}
+}
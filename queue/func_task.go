@@ -0,0 +1,84 @@
+/*
+ * @Time   : 2026/10/16 下午2:00
+ * @Email  : jjonline@jjonline.cn
+ */
+package queue
+
+import (
+	"context"
+	"time"
+)
+
+// FuncTaskOption 函数任务类可选项
+type FuncTaskOption func(task *funcTask)
+
+// funcTask 由执行方法包装而成的任务类
+// implement TaskIFace
+type funcTask struct {
+	name          string        // 队列名称
+	fn            ExecuteFunc   // 执行方法
+	maxTries      int64         // 最大尝试次数
+	retryInterval int64         // 重试间隔，单位：秒
+	timeout       time.Duration // 超时时长
+}
+
+// newFuncTask 包装执行方法为任务类，未指定的可选项使用 DefaultTaskSetting 相同的默认值
+func newFuncTask(name string, fn ExecuteFunc, opts []FuncTaskOption) *funcTask {
+	task := &funcTask{
+		name:          name,
+		fn:            fn,
+		maxTries:      DefaultMaxTries,
+		retryInterval: DefaultRetryInterval,
+		timeout:       DefaultMaxExecuteDuration,
+	}
+	for _, opt := range opts {
+		opt(task)
+	}
+	return task
+}
+
+// WithMaxTries 指定函数任务类的最大尝试次数，0表示无限重试
+func WithMaxTries(maxTries int64) FuncTaskOption {
+	return func(task *funcTask) {
+		task.maxTries = maxTries
+	}
+}
+
+// WithRetryInterval 指定函数任务类执行失败后再次尝试执行的间隔时长，单位：秒
+func WithRetryInterval(seconds int64) FuncTaskOption {
+	return func(task *funcTask) {
+		task.retryInterval = seconds
+	}
+}
+
+// WithTimeout 指定函数任务类的超时时长
+func WithTimeout(timeout time.Duration) FuncTaskOption {
+	return func(task *funcTask) {
+		task.timeout = timeout
+	}
+}
+
+// MaxTries implement TaskIFace
+func (task *funcTask) MaxTries() int64 {
+	return task.maxTries
+}
+
+// RetryInterval implement TaskIFace
+func (task *funcTask) RetryInterval() int64 {
+	return task.retryInterval
+}
+
+// Timeout implement TaskIFace
+func (task *funcTask) Timeout() time.Duration {
+	return task.timeout
+}
+
+// Name implement TaskIFace
+func (task *funcTask) Name() string {
+	return task.name
+}
+
+// Execute implement TaskIFace
+func (task *funcTask) Execute(ctx context.Context, job *RawBody) error {
+	return task.fn(ctx, job)
+}
@@ -1,9 +1,24 @@
 module github.com/jjonline/go-lib-backend/queue
 
-go 1.16
+go 1.18
 
 require (
 	github.com/go-redis/redis/v8 v8.8.3
 	github.com/google/uuid v1.2.0
+	github.com/robfig/cron/v3 v3.0.1
+	go.uber.org/goleak v1.1.10
 	go.uber.org/zap v1.18.1
+	golang.org/x/time v0.0.0-20210723032227-1f47c861a9ac
+)
+
+require (
+	github.com/cespare/xxhash/v2 v2.1.1 // indirect
+	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
+	go.opentelemetry.io/otel v0.20.0 // indirect
+	go.opentelemetry.io/otel/metric v0.20.0 // indirect
+	go.opentelemetry.io/otel/trace v0.20.0 // indirect
+	go.uber.org/atomic v1.7.0 // indirect
+	go.uber.org/multierr v1.6.0 // indirect
+	golang.org/x/lint v0.0.0-20190930215403-16217165b5de // indirect
+	golang.org/x/tools v0.0.0-20201224043029-2b0845dc783e // indirect
 )
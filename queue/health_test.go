@@ -0,0 +1,48 @@
+/*
+ * @Time   : 2026/10/19 上午10:00
+ * @Email  : jjonline@jjonline.cn
+ */
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestHealthyStuckWindow 所有worker执行中且超过卡死判定时长无变化时不健康，卡死判定时长启动后不可再修改
+func TestHealthyStuckWindow(t *testing.T) {
+	release := make(chan struct{})
+	task := &countTask{name: "healthy_stuck", handler: func(ctx context.Context, job *RawBody) error {
+		<-release
+		return nil
+	}}
+	q := newTestQueue(t, 1, task)
+	if err := q.SetStuckWindow(-time.Second); err == nil {
+		t.Fatalf("expected error for negative stuck window")
+	}
+	if err := q.SetStuckWindow(200 * time.Millisecond); err != nil {
+		t.Fatalf("set stuck window: %v", err)
+	}
+	if q.Healthy() {
+		t.Fatalf("expected not healthy before start")
+	}
+
+	if err := q.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer q.ShutDown(context.Background())
+	defer close(release)
+
+	if err := q.SetStuckWindow(time.Second); err != ErrQueueStarted {
+		t.Fatalf("expected ErrQueueStarted after start, got %v", err)
+	}
+	if !q.Healthy() {
+		t.Fatalf("expected healthy after start")
+	}
+
+	if err := q.Dispatch(task, "stuck"); err != nil {
+		t.Fatalf("dispatch: %v", err)
+	}
+	waitFor(t, 3*time.Second, func() bool { return !q.Healthy() })
+}
@@ -0,0 +1,90 @@
+/*
+ * @Time   : 2026/10/17 上午10:00
+ * @Email  : jjonline@jjonline.cn
+ */
+package queue
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countTask 测试用任务类：记录执行次数，handler不为nil时由其决定执行结果
+type countTask struct {
+	DefaultTaskSetting
+	name          string
+	maxTries      int64
+	retryInterval int64
+	timeout       time.Duration
+	executed      int64
+	handler       func(ctx context.Context, job *RawBody) error
+}
+
+func (task *countTask) Name() string {
+	return task.name
+}
+
+func (task *countTask) MaxTries() int64 {
+	if task.maxTries > 0 {
+		return task.maxTries
+	}
+	return DefaultMaxTries
+}
+
+func (task *countTask) RetryInterval() int64 {
+	return task.retryInterval
+}
+
+func (task *countTask) Timeout() time.Duration {
+	if task.timeout > 0 {
+		return task.timeout
+	}
+	return DefaultMaxExecuteDuration
+}
+
+func (task *countTask) Execute(ctx context.Context, job *RawBody) error {
+	atomic.AddInt64(&task.executed, 1)
+	if task.handler != nil {
+		return task.handler(ctx, job)
+	}
+	return nil
+}
+
+func (task *countTask) count() int64 {
+	return atomic.LoadInt64(&task.executed)
+}
+
+// scheduledCountTask 测试用周期任务类
+type scheduledCountTask struct {
+	countTask
+}
+
+func (task *scheduledCountTask) Schedule() string {
+	return "@every 1s"
+}
+
+// newTestQueue 使用memory驱动、不输出日志的测试队列
+func newTestQueue(t *testing.T, concurrent int64, tasks ...TaskIFace) *Queue {
+	t.Helper()
+
+	q := NewWithLogger(Memory, nil, NopLogger(), concurrent)
+	if err := q.Bootstrap(tasks); err != nil {
+		t.Fatalf("bootstrap: %v", err)
+	}
+	return q
+}
+
+// waitFor 轮询等待条件成立，超时后测试失败
+func waitFor(t *testing.T, timeout time.Duration, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(timeout)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("condition not met within %s", timeout)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,46 @@
+/*
+ * @Time   : 2026/10/16 下午4:00
+ * @Email  : jjonline@jjonline.cn
+ */
+package queue
+
+import (
+	"context"
+)
+
+// workerIDContextKey 执行上下文中执行job的workerID的key
+type workerIDContextKey struct{}
+
+// jobIDContextKey 执行上下文中job ID的key
+type jobIDContextKey struct{}
+
+// attemptsContextKey 执行上下文中job尝试执行次数的key
+type attemptsContextKey struct{}
+
+// withJobInfo 将执行job的workerID、job ID以及本次为第几次尝试执行写入执行上下文
+func withJobInfo(ctx context.Context, workerID int64, job JobIFace) context.Context {
+	ctx = context.WithValue(ctx, workerIDContextKey{}, workerID)
+	ctx = context.WithValue(ctx, jobIDContextKey{}, job.Payload().ID)
+	return context.WithValue(ctx, attemptsContextKey{}, job.Attempts())
+}
+
+// WorkerIDFromContext 从任务类 Execute 的ctx中获取执行该job的workerID，与队列worker日志中的worker_id一致
+// 非队列执行上下文时第二个返回值为false
+func WorkerIDFromContext(ctx context.Context) (workerID int64, ok bool) {
+	workerID, ok = ctx.Value(workerIDContextKey{}).(int64)
+	return workerID, ok
+}
+
+// JobIDFromContext 从任务类 Execute 的ctx中获取当前执行的job ID，即 Payload.ID
+// 非队列执行上下文时第二个返回值为false
+func JobIDFromContext(ctx context.Context) (jobID string, ok bool) {
+	jobID, ok = ctx.Value(jobIDContextKey{}).(string)
+	return jobID, ok
+}
+
+// AttemptsFromContext 从任务类 Execute 的ctx中获取本次为第几次尝试执行，首次执行为1，大于1即为重试
+// 可据此在重试时跳过发送“已开始”通知等仅需首次执行的逻辑；非队列执行上下文时第二个返回值为false
+func AttemptsFromContext(ctx context.Context) (attempts int64, ok bool) {
+	attempts, ok = ctx.Value(attemptsContextKey{}).(int64)
+	return attempts, ok
+}
@@ -0,0 +1,51 @@
+/*
+ * @Time   : 2026/10/16 下午19:00
+ * @Email  : jjonline@jjonline.cn
+ */
+package queue
+
+import (
+	"time"
+)
+
+// JobEventBufferSize job状态变化事件通道的缓冲大小，缓冲已满时丢弃新事件
+const JobEventBufferSize = 1024
+
+// subscribeEvents 订阅job状态变化事件：首次调用后开始发送事件，未订阅时不发送
+func (m *manager) subscribeEvents() <-chan JobEvent {
+	m.eventsEnabled.setTrue()
+	return m.events
+}
+
+// emitJobEvent 非阻塞发送job状态变化事件：未订阅或通道缓冲已满时直接丢弃，不影响job执行
+// @param workerID 执行job的workerID，无法确定时传-1
+func (m *manager) emitJobEvent(eventType JobEventType, job JobIFace, workerID int64, err error) {
+	if !m.eventsEnabled.isSet() {
+		return
+	}
+
+	event := JobEvent{
+		Type:     eventType,
+		ID:       job.Payload().ID,
+		Name:     jobQueueName(job),
+		WorkerID: workerID,
+		Attempts: job.Attempts(),
+		Err:      err,
+		Time:     time.Now(),
+	}
+	select {
+	case m.events <- event:
+	default:
+	}
+}
+
+// workingWorkerID 获取执行中job所在的workerID，job不在执行中时返回-1
+func (m *manager) workingWorkerID(job JobIFace) int64 {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if working, ok := m.inWorkingMap[job.Payload().ID]; ok && working.job == job {
+		return working.workerID
+	}
+	return -1
+}
@@ -2,6 +2,7 @@ package queue
 
 import (
 	"fmt"
+	"sync"
 	"time"
 )
 
@@ -12,6 +13,8 @@ import (
 
 type JobMemory struct {
 	basic       queueBasic
+	lock        *sync.Mutex                      // 所属memory队列的锁，延迟map、保留map与队列共用
+	now         func() time.Time                 // 所属memory队列的时钟
 	delayed     map[string]map[string]*itemValue // 延迟map ref type
 	reserved    map[string]map[string]*itemValue // 保留map ref type
 	reservedJob Payload                          // 处理后的保留状态的job
@@ -19,6 +22,9 @@ type JobMemory struct {
 }
 
 func (job *JobMemory) Release(delay int64) (err error) {
+	job.lock.Lock()
+	defer job.lock.Unlock()
+
 	job.isReleased = true
 
 	if _, exist := job.reserved[job.GetName()]; !exist {
@@ -39,7 +45,7 @@ func (job *JobMemory) Release(delay int64) (err error) {
 	// 移动到延迟队列
 	itemV := itemValue{
 		Payload: job.reservedJob,
-		TimeAt:  time.Now().Add(time.Duration(delay) * time.Second).Unix(),
+		TimeAt:  job.now().Add(time.Duration(delay) * time.Second).Unix(),
 	}
 	job.delayed[job.GetName()][job.payload.ID] = &itemV
 
@@ -47,6 +53,9 @@ func (job *JobMemory) Release(delay int64) (err error) {
 }
 
 func (job *JobMemory) Delete() (err error) {
+	job.lock.Lock()
+	defer job.lock.Unlock()
+
 	job.isDeleted = true
 
 	if _, exist := job.reserved[job.GetName()]; !exist {
@@ -64,10 +73,14 @@ func (job *JobMemory) Delete() (err error) {
 }
 
 func (job *JobMemory) IsDeleted() (deleted bool) {
+	job.lock.Lock()
+	defer job.lock.Unlock()
 	return job.isDeleted
 }
 
 func (job *JobMemory) IsReleased() (released bool) {
+	job.lock.Lock()
+	defer job.lock.Unlock()
 	return job.isReleased
 }
 
@@ -90,10 +103,14 @@ func (job *JobMemory) TimeoutAt() (time time.Time) {
 }
 
 func (job *JobMemory) HasFailed() (hasFail bool) {
+	job.lock.Lock()
+	defer job.lock.Unlock()
 	return job.hasFailed
 }
 
 func (job *JobMemory) MarkAsFailed() {
+	job.lock.Lock()
+	defer job.lock.Unlock()
 	job.hasFailed = true
 }
 
@@ -70,7 +70,7 @@ func (job *JobRedis) Attempts() (attempt int64) {
 	return job.payload.Attempts + 1
 }
 
-// PopTime 任务job首次被执行的时刻
+// PopTime 任务job本次被取出执行的时刻
 func (job *JobRedis) PopTime() (time time.Time) {
 	return job.popTime
 }
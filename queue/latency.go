@@ -0,0 +1,87 @@
+/*
+ * @Time   : 2026/10/16 下午6:00
+ * @Email  : jjonline@jjonline.cn
+ */
+package queue
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// LatencyWindowSize 每个队列用于计算执行时长分位数的最近样本数
+const LatencyWindowSize = 1024
+
+// latencyWindow 固定容量的执行时长样本环形缓冲区，写满后新样本覆盖最旧的样本
+type latencyWindow struct {
+	lock    sync.Mutex
+	samples []time.Duration
+	next    int // 下一个样本写入位置
+}
+
+// add 写入一个执行时长样本
+func (w *latencyWindow) add(duration time.Duration) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if len(w.samples) < LatencyWindowSize {
+		w.samples = append(w.samples, duration)
+		return
+	}
+	w.samples[w.next] = duration
+	w.next = (w.next + 1) % LatencyWindowSize
+}
+
+// stats 计算当前样本的分位数
+func (w *latencyWindow) stats() LatencyStats {
+	w.lock.Lock()
+	sorted := make([]time.Duration, len(w.samples))
+	copy(sorted, w.samples)
+	w.lock.Unlock()
+
+	if len(sorted) == 0 {
+		return LatencyStats{}
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return LatencyStats{
+		Samples: len(sorted),
+		P50:     percentile(sorted, 50),
+		P95:     percentile(sorted, 95),
+		P99:     percentile(sorted, 99),
+	}
+}
+
+// percentile 按最近秩法取已排序样本的分位数
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (len(sorted)*p + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// recordLatency 记录队列执行成功job的执行时长
+func (m *manager) recordLatency(name string, duration time.Duration) {
+	m.lock.Lock()
+	window, ok := m.latencies[name]
+	if !ok {
+		window = &latencyWindow{}
+		m.latencies[name] = window
+	}
+	m.lock.Unlock()
+
+	window.add(duration)
+}
+
+// latencyStats 获取队列执行时长分位数，无样本时返回零值
+func (m *manager) latencyStats(name string) LatencyStats {
+	m.lock.Lock()
+	window, ok := m.latencies[name]
+	m.lock.Unlock()
+
+	if !ok {
+		return LatencyStats{}
+	}
+	return window.stats()
+}
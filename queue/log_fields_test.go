@@ -0,0 +1,44 @@
+/*
+ * @Time   : 2026/10/18 下午15:00
+ * @Email  : jjonline@jjonline.cn
+ */
+package queue
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// logFieldsCountTask 测试用任务类：记录 LogFields 调用次数
+type logFieldsCountTask struct {
+	countTask
+	logFields int64
+}
+
+func (task *logFieldsCountTask) LogFields(job *RawBody) []Field {
+	atomic.AddInt64(&task.logFields, 1)
+	return []Field{field("tenant", job.String())}
+}
+
+// TestLogFieldsComputedOncePerExecution 每次执行job仅调用一次 LogFields，本次执行的所有日志复用
+func TestLogFieldsComputedOncePerExecution(t *testing.T) {
+	fail := func(ctx context.Context, job *RawBody) error { return errors.New("always fail") }
+	task := &logFieldsCountTask{countTask: countTask{name: "log_fields", maxTries: 2, handler: fail}}
+	q := newTestQueue(t, 1, task)
+	if err := q.Dispatch(task, "tenant-1"); err != nil {
+		t.Fatalf("dispatch: %v", err)
+	}
+	if err := q.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer q.ShutDown(context.Background())
+
+	waitFor(t, 5*time.Second, func() bool { return task.count() == 2 && q.queue.Size(task.Name()) == 0 })
+	time.Sleep(100 * time.Millisecond)
+	if calls := atomic.LoadInt64(&task.logFields); calls != 2 {
+		t.Fatalf("expected LogFields called once per execution, got %d", calls)
+	}
+}
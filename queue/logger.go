@@ -0,0 +1,168 @@
+/*
+ * @Time   : 2026/10/15 下午18:00
+ * @Email  : jjonline@jjonline.cn
+ */
+package queue
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// *************************************************
+// 队列日志记录器抽象
+// 1、队列内部日志均通过 Logger 接口输出，可接入slog、logrus等任意日志组件
+// 2、提供zap适配实现 NewZapLogger，New 传入的zap日志实例即使用该适配
+// 3、提供不输出任何日志的 NopLogger，便于测试
+// *************************************************
+
+// Field 日志结构化字段
+type Field struct {
+	Key   string      // 字段名
+	Value interface{} // 字段值
+}
+
+// field 构造日志结构化字段
+func field(key string, value interface{}) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Logger 队列日志记录器契约
+type Logger interface {
+	Debug(msg string, fields ...Field)
+	Info(msg string, fields ...Field)
+	Warn(msg string, fields ...Field)
+	Error(msg string, fields ...Field)
+}
+
+// region zap适配
+
+// zapLogger 基于zap实现的日志记录器
+type zapLogger struct {
+	logger *zap.Logger
+}
+
+// NewZapLogger 将zap日志实例适配为队列日志记录器，传nil则不输出任何日志
+func NewZapLogger(logger *zap.Logger) Logger {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &zapLogger{logger: logger}
+}
+
+func (l *zapLogger) Debug(msg string, fields ...Field) {
+	l.logger.Debug(msg, zapFields(fields)...)
+}
+
+func (l *zapLogger) Info(msg string, fields ...Field) {
+	l.logger.Info(msg, zapFields(fields)...)
+}
+
+func (l *zapLogger) Warn(msg string, fields ...Field) {
+	l.logger.Warn(msg, zapFields(fields)...)
+}
+
+func (l *zapLogger) Error(msg string, fields ...Field) {
+	l.logger.Error(msg, zapFields(fields)...)
+}
+
+// zapFields 转换为zap字段：值为nil（例如nil error）的字段忽略
+func zapFields(fields []Field) []zap.Field {
+	zapFields := make([]zap.Field, 0, len(fields))
+	for _, f := range fields {
+		if f.Value == nil {
+			continue
+		}
+		zapFields = append(zapFields, zap.Any(f.Key, f.Value))
+	}
+	return zapFields
+}
+
+// endregion
+
+// region 附加字段
+
+// fieldsLogger 每条日志均附加固定字段的日志记录器
+type fieldsLogger struct {
+	logger Logger
+	fields []Field
+}
+
+// withFields 基于日志记录器派生每条日志均附加指定字段的日志记录器
+func withFields(logger Logger, fields []Field) Logger {
+	return &fieldsLogger{logger: logger, fields: fields}
+}
+
+func (l *fieldsLogger) Debug(msg string, fields ...Field) {
+	l.logger.Debug(msg, append(fields, l.fields...)...)
+}
+
+func (l *fieldsLogger) Info(msg string, fields ...Field) {
+	l.logger.Info(msg, append(fields, l.fields...)...)
+}
+
+func (l *fieldsLogger) Warn(msg string, fields ...Field) {
+	l.logger.Warn(msg, append(fields, l.fields...)...)
+}
+
+func (l *fieldsLogger) Error(msg string, fields ...Field) {
+	l.logger.Error(msg, append(fields, l.fields...)...)
+}
+
+// endregion
+
+// region 空日志
+
+// nopLogger 不输出任何日志的日志记录器
+type nopLogger struct{}
+
+// NopLogger 不输出任何日志的日志记录器
+func NopLogger() Logger {
+	return nopLogger{}
+}
+
+func (nopLogger) Debug(msg string, fields ...Field) {}
+func (nopLogger) Info(msg string, fields ...Field)  {}
+func (nopLogger) Warn(msg string, fields ...Field)  {}
+func (nopLogger) Error(msg string, fields ...Field) {}
+
+// endregion
+
+// region 级别过滤
+
+// levelLogger 带最低级别过滤的日志记录器：低于最低级别的日志直接丢弃
+type levelLogger struct {
+	logger Logger
+	level  zapcore.Level
+}
+
+// newLevelLogger 基于日志记录器派生带最低级别过滤的日志记录器
+func newLevelLogger(logger Logger, level zapcore.Level) Logger {
+	return &levelLogger{logger: logger, level: level}
+}
+
+func (l *levelLogger) Debug(msg string, fields ...Field) {
+	if l.level.Enabled(zapcore.DebugLevel) {
+		l.logger.Debug(msg, fields...)
+	}
+}
+
+func (l *levelLogger) Info(msg string, fields ...Field) {
+	if l.level.Enabled(zapcore.InfoLevel) {
+		l.logger.Info(msg, fields...)
+	}
+}
+
+func (l *levelLogger) Warn(msg string, fields ...Field) {
+	if l.level.Enabled(zapcore.WarnLevel) {
+		l.logger.Warn(msg, fields...)
+	}
+}
+
+func (l *levelLogger) Error(msg string, fields ...Field) {
+	if l.level.Enabled(zapcore.ErrorLevel) {
+		l.logger.Error(msg, fields...)
+	}
+}
+
+// endregion
@@ -38,6 +38,26 @@ if(job ~= false) then
 	redis.call('zadd', KEYS[2], timeoutAt, reserved)
 end
 
+return {job, reserved}
+`)
+	popMsgpack = redis.NewScript(`
+-- Same as pop, but the job is serialized by msgpack...
+local job = redis.call('lpop', KEYS[1])
+local reserved = false
+local timeoutAt = 0
+
+if(job ~= false) then
+	reserved = cmsgpack.unpack(job)
+	if reserved['PopTime'] <= 0 then
+		reserved['PopTime'] = tonumber(ARGV[1])
+	end
+	timeoutAt = tonumber(ARGV[1]) + tonumber(reserved['Timeout'])
+	reserved['Attempts'] = reserved['Attempts'] + 1
+	reserved['TimeoutAt'] = timeoutAt
+	reserved = cmsgpack.pack(reserved)
+	redis.call('zadd', KEYS[2], timeoutAt, reserved)
+end
+
 return {job, reserved}
 `)
 	release = redis.NewScript(`
@@ -65,6 +85,24 @@ if(next(val) ~= nil) then
 end
 
 return val
+`)
+	pushUnique = redis.NewScript(`
+-- Only push the job onto the queue when the unique key is not exist...
+if(redis.call('set', KEYS[2], '1', 'NX', 'PX', ARGV[2])) then
+    redis.call('rpush', KEYS[1], ARGV[1])
+    return 1
+end
+
+return 0
+`)
+	requeue = redis.NewScript(`
+-- Remove the job from the reserved queue, only push it onto the queue when removed...
+if(redis.call('zrem', KEYS[1], ARGV[1]) == 1) then
+    redis.call('rpush', KEYS[2], ARGV[1])
+    return 1
+end
+
+return 0
 `)
 )
 
@@ -96,6 +134,20 @@ func (lua *luaScripts) Pop() *redis.Script {
 	return pop
 }
 
+// PopMsgpack
+/**
+ * Get the Lua script for popping the next msgpack serialized job off of the queue.
+ *
+ * KEYS[1] - The queue to pop jobs from, for example: queues:foo
+ * KEYS[2] - The queue to place reserved jobs on, for example: queues:foo:reserved
+ * ARGV[1] - The Now unix time
+ *
+ * @return string
+ */
+func (lua *luaScripts) PopMsgpack() *redis.Script {
+	return popMsgpack
+}
+
 // Release
 /**
  * Get the Lua script for releasing reserved jobs.
@@ -124,3 +176,32 @@ func (lua *luaScripts) Release() *redis.Script {
 func (lua *luaScripts) MigrateExpiredJobs() *redis.Script {
 	return migrate
 }
+
+// Requeue
+/**
+ * Get the Lua script to move a reserved job back onto the queue.
+ *
+ * KEYS[1] - The queue we are removing the job from, for example: queues:foo:reserved
+ * KEYS[2] - The queue we are moving the job to, for example: queues:foo
+ * ARGV[1] - The raw payload of the reserved job
+ *
+ * @return int 1 when moved, 0 when the job is no longer reserved
+ */
+func (lua *luaScripts) Requeue() *redis.Script {
+	return requeue
+}
+
+// PushUnique
+/**
+ * Get the Lua script to push a job onto the queue only once within the unique window.
+ *
+ * KEYS[1] - The queue to push the job onto, for example: queues:foo
+ * KEYS[2] - The unique key of the job, for example: queues:foo:unique:bar
+ * ARGV[1] - The raw payload of the job
+ * ARGV[2] - The unique window in milliseconds
+ *
+ * @return int 1 when pushed, 0 when the unique key already exists
+ */
+func (lua *luaScripts) PushUnique() *redis.Script {
+	return pushUnique
+}
@@ -6,12 +6,23 @@ package queue
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"golang.org/x/time/rate"
+	"hash/crc32"
 	"math/rand"
+	"os"
+	"os/signal"
+	"reflect"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
+	"syscall"
 	"time"
 )
 
@@ -22,8 +33,32 @@ import (
 // 3、队列相关管控功能实现：启动、优雅停止、协程并发调度等
 // *************************************************
 
-// jitterBase looper最小为450毫秒间隔，最大为1000毫秒间隔
-var	jitterBase = 450 * time.Millisecond
+// partitionRedeliverDelay 分区执行中的job数已达上限时job再次投递的延迟时长
+const partitionRedeliverDelay = time.Second
+
+// executeLimitRedeliverDelay 全局执行并发数已达上限时job再次投递的延迟
+const executeLimitRedeliverDelay = time.Second
+
+// defaultUnregisteredDelay 找不到任务类的job默认再次投递的延迟
+const defaultUnregisteredDelay = time.Minute
+
+// unregisteredHeader 记录job因找不到任务类而再次投递次数的元数据头
+const unregisteredHeader = "X-Queue-Unregistered"
+
+// waitInFlightPollInterval 等待执行中job结束的检查间隔
+const waitInFlightPollInterval = 50 * time.Millisecond
+
+// backpressurePollInterval 所有worker均在执行时looper等待worker空闲的检查间隔
+const backpressurePollInterval = 10 * time.Millisecond
+
+// defaultPromoteInterval 默认延迟任务晋升扫描间隔
+const defaultPromoteInterval = time.Second
+
+// 默认looper空闲休眠间隔：最小为450毫秒间隔，最大为1000毫秒间隔
+const (
+	defaultLooperIntervalMin = 450 * time.Millisecond
+	defaultLooperIntervalMax = 1 * time.Second
+)
 
 type atomicBool int32
 
@@ -31,58 +66,401 @@ func (b *atomicBool) isSet() bool { return atomic.LoadInt32((*int32)(b)) != 0 }
 func (b *atomicBool) setTrue()    { atomic.StoreInt32((*int32)(b), 1) }
 func (b *atomicBool) setFalse()   { atomic.StoreInt32((*int32)(b), 0) }
 
+// 执行中job的处理状态：执行协程与强制关闭放回通过状态变更互斥，仅变更成功的一方处理job
+const (
+	workingPreparing int32 = iota // 执行前检查中
+	workingExecuting              // 任务类执行中
+	workingSettled                // 处理结果已落定
+)
+
+// workingJob 执行中的job信息
+type workingJob struct {
+	workerID int64              // 执行该job的workerID
+	job      JobIFace           // 执行中的job
+	task     TaskIFace          // job对应的任务类
+	state    int32              // job处理状态
+	cancel   context.CancelFunc // 取消job执行ctx的方法，开始执行前为nil，读写需持有manager的锁
+	canceled atomicBool         // 原子态标记：是否已被手动取消
+}
+
+// transit job处理状态由from变更为to，返回是否变更成功
+func (w *workingJob) transit(from, to int32) bool {
+	return atomic.CompareAndSwapInt32(&w.state, from, to)
+}
+
 // manager 队列管理者，队列的调度执行和管理
 type manager struct {
-	queue            QueueIFace            // 队列底层实现实例
-	channel          chan JobIFace         // 任务类执行job的通道chan
-	logger           *zap.Logger           // zap logger
-	concurrent       int64                 // 单个队列最大并发worker数
-	tasks            map[string]TaskIFace  // 队列名与任务类实例映射map，interface无需显式指定执指针类型，但实际传参需指针类型
-	failedJobHandler FailedJobHandler      // 失败任务[最大尝试次数后仍然尝试失败（Execute返回了Error 或 执行导致panic）的任务]处理器
-	lock             sync.Mutex            // 并发锁
-	doneChan         chan struct{}         // 关闭队列的信号控制chan
-	inShutdown       atomicBool            // 原子态标记：是否处于优雅关闭状态中
-	inWorkingMap     map[string]int64      // 当前正work中的jobID与workerID映射map
-	workerStatus     map[int64]*atomicBool // worker工作进程状态标记map
-	jitter           time.Duration         // 循环器抖动间隔
+	queue               QueueIFace                                // 队列底层实现实例
+	channel             chan []JobIFace                           // 任务类执行job的通道chan，每次投递同一队列的一批job（默认每批1个）
+	fastChannel         chan []JobIFace                           // 快速队列专用通道chan，仅设置了快速队列时初始化
+	fastQueues          map[string]bool                           // 快速队列名称集合
+	fastWorkers         int64                                     // 为快速队列预留的worker数量
+	dedicatedPools      map[string]*dedicatedPool                 // 队列名称与其专用worker池映射map
+	dedicatedWorkers    map[int64]*dedicatedPool                  // 专用worker的workerID与其所属worker池映射map
+	logger              Logger                                    // 日志记录器
+	looperLogger        Logger                                    // looper组件日志记录器
+	workerLogger        Logger                                    // worker组件日志记录器（含job执行相关日志）
+	shutdownLogger      Logger                                    // 优雅关闭组件日志记录器
+	concurrent          int64                                     // 单个队列最大并发worker数
+	loopers             int                                       // looper协程数量，默认1
+	popBatchSize        int64                                     // looper每次从单个队列取出的批数，默认1
+	looperWg            sync.WaitGroup                            // 等待所有looper协程退出
+	producerWg          sync.WaitGroup                            // 等待周期任务调度器、延迟任务晋升协程退出
+	producerDone        chan struct{}                             // 通知周期任务调度器、延迟任务晋升协程退出的chan
+	scheduleChanged     chan struct{}                             // 通知周期任务调度器同步调度条目的chan，缓冲为1
+	liveWorkers         int64                                     // 已启动尚未退出的worker协程数量，原子读写
+	idleWorkers         int64                                     // 空闲的普通worker数量，原子读写
+	idleFastWorkers     int64                                     // 空闲的快速队列预留worker数量，原子读写
+	looperIntervalMin   time.Duration                             // looper空闲休眠最小间隔
+	looperIntervalMax   time.Duration                             // looper空闲休眠最大间隔
+	promoteInterval     time.Duration                             // 延迟任务晋升扫描间隔，仅队列实现了 DelayedPromoter 时生效
+	defaultTimeout      time.Duration                             // job超时时长为0时使用的默认超时时长
+	maxTimeout          time.Duration                             // job超时时长上限，0为不限制
+	strictStart         bool                                      // 严格启动模式：没有已注册任务类时启动返回error
+	forceRequeue        bool                                      // 优雅关闭超时后将执行中的job放回队列
+	quietPeriod         time.Duration                             // 优雅关闭时所有worker空闲后返回前的静默等待时长，0为不等待
+	unregisteredDelay   time.Duration                             // 找不到任务类的job再次投递的延迟
+	unregisteredMax     int64                                     // 找不到任务类的job最多再次投递的次数，超过后按最终失败处理，0为不限制
+	driftThreshold      time.Duration                             // job投递延迟告警阈值，0为不告警
+	stuckWindow         time.Duration                             // 所有worker均在执行且状态无变化超过该时长视为卡死，0为不检查
+	lastActivity        int64                                     // worker状态最近一次变化的时刻，unix纳秒时间戳，原子读写
+	loopersInPass       int64                                     // 正在遍历任务类取出job的looper数量，原子读写
+	undoneBatches       int64                                     // 已投递给worker但尚未执行结束的job批次数量，原子读写
+	looperBusyNanos     int64                                     // 所有looper累计遍历队列取出、投递job以及等待空闲worker的纳秒数，原子读写
+	looperIdleNanos     int64                                     // 所有looper累计因所有队列均无job而休眠的纳秒数，原子读写
+	tasks               map[string]TaskIFace                      // 队列名与任务类实例映射map，interface无需显式指定执指针类型，但实际传参需指针类型
+	aliases             map[string]TaskIFace                      // 任务类旧名称（别名）与任务类的映射，仅用于消费
+	failedJobHandlers   []FailedJobHandler                        // 失败任务[最大尝试次数后仍然尝试失败（Execute返回了Error 或 执行导致panic）的任务]处理器，按注册顺序调用
+	failedRecordHandler FailedRecordHandler                       // 失败任务记录处理器，接收任务类自定义的失败记录
+	duplicatePolicy     DuplicatePolicy                           // 执行中重复job处理策略，未设置则再次投递
+	reserveTimeout      time.Duration                             // 执行中重复job再次投递的延迟以及等待时长，0为按job设置
+	recentCompleted     *recentJobs                               // 最近执行成功的job ID缓存，用于丢弃被再次投递的已完成job，nil为不启用
+	panicNormalizer     PanicNormalizer                           // panic值规范化为error的方法，未设置则使用默认方法
+	panicHandler        PanicHandler                              // 任务类执行panic时的处理方法，未设置则仅记录日志
+	progressSink        ProgressSink                              // job执行进度接收方法，未设置则丢弃进度
+	resultStore         ResultStore                               // job执行结果存储，未设置则丢弃执行结果
+	codec               Codec                                     // 任务参数编解码器，用于执行前解码已编码的任务参数
+	payloadCodec        PayloadCodec                              // job序列化器，用于再次投递、死信队列等场景序列化job，未设置则使用json
+	basic               *queueBasic                               // 所属Queue的队列基础方法，周期任务投递job时使用其编码、超时等设置
+	backoffStrategy     BackoffStrategy                           // 重试间隔策略，未设置则使用固定间隔
+	retryJitter         int                                       // 重试延迟随机抖动百分比，0为不抖动
+	deadLetterSuffix    string                                    // 死信队列名称后缀，为空则不启用死信队列
+	metrics             MetricsCollector                          // 指标采集器，未设置则不采集
+	middlewares         []Middleware                              // 任务类执行中间件，按注册顺序由外至内包装
+	executors           map[string]cachedExecutor                 // 任务类名称与已包装中间件的执行方法映射，首次执行时构建
+	onQueueEmpty        func(name string)                         // 队列由非空变为空时的回调
+	onLongRunning       func(job JobIFace, elapsed time.Duration) // job执行时长超过超时时长时的回调
+	onJobOutcome        JobOutcomeHandler                         // job处理结果回调
+	events              chan JobEvent                             // job状态变化事件通道
+	eventsEnabled       atomicBool                                // 原子态标记：是否已订阅job状态变化事件
+	onShutdownProgress  func(remaining int)                       // 优雅关闭等待期间每次检查时的进度回调
+	longRunningNotified map[string]bool                           // 已触发执行时长超限告警的jobID集合
+	lock                sync.Mutex                                // 并发锁
+	doneChan            chan struct{}                             // 关闭队列的信号控制chan
+	inShutdown          atomicBool                                // 原子态标记：是否处于优雅关闭状态中
+	inStarted           atomicBool                                // 原子态标记：是否已启动
+	paused              atomicBool                                // 原子态标记：是否暂停取出job
+	inWorkingMap        map[string]*workingJob                    // 当前正work中的jobID与执行中job信息映射map，读写均需持有lock
+	partitionInFlight   map[string]map[string]int64               // 各队列各分区执行中的job数量
+	taskInFlight        map[string]int64                          // 实现了 ConcurrencyTask 的任务类已取出执行中（含投递中）的job数量
+	latencies           map[string]*latencyWindow                 // 各队列最近执行成功job的执行时长样本
+	limiters            map[string]*rate.Limiter                  // 实现了 RateLimitTask 的任务类名称与令牌桶限速器映射
+	breakers            map[string]*circuitBreaker                // 实现了 CircuitBreakerTask 的任务类名称与熔断器映射
+	executeSemaphore    chan struct{}                             // 全局执行并发信号量，未设置全局执行并发数时为nil
+	executeLimitAction  ExecuteLimitAction                        // 全局执行并发数已达上限时的处理动作
+	workerStatus        map[int64]*atomicBool                     // worker工作进程状态标记map
+	workerQuit          map[int64]chan struct{}                   // 普通worker的退出信号chan，缩容时关闭以通知worker执行完当前job后退出
+	nextWorkerID        int64                                     // 扩容时新worker的ID
 }
 
 // newManager 实例化一个manager
 // @param queue      队列实现底层实例指针
-// @param logger     zap日志实例
+// @param logger     日志记录器
 // @param concurrent 队列实际执行并发worker工作者数量
-func newManager(queue QueueIFace, logger *zap.Logger, concurrent int64) *manager {
+func newManager(queue QueueIFace, logger Logger, concurrent int64) *manager {
+	if concurrent <= 0 {
+		concurrent = AutoConcurrency()
+	}
+
 	return &manager{
-		queue:        queue,
-		channel:      make(chan JobIFace), // no buffer channel, execute when worker received
-		logger:       logger,
-		concurrent:   concurrent,
-		tasks:        make(map[string]TaskIFace),
-		workerStatus: make(map[int64]*atomicBool, concurrent),
-		inWorkingMap: make(map[string]int64),
-		lock:         sync.Mutex{},
-		jitter:       450 * time.Millisecond,
+		queue:               queue,
+		channel:             make(chan []JobIFace), // no buffer channel, execute when worker received
+		producerDone:        make(chan struct{}),
+		scheduleChanged:     make(chan struct{}, 1),
+		logger:              logger,
+		looperLogger:        logger,
+		workerLogger:        logger,
+		shutdownLogger:      logger,
+		concurrent:          concurrent,
+		loopers:             1,
+		popBatchSize:        1,
+		looperIntervalMin:   defaultLooperIntervalMin,
+		looperIntervalMax:   defaultLooperIntervalMax,
+		metrics:             nopMetrics{},
+		executors:           make(map[string]cachedExecutor),
+		promoteInterval:     defaultPromoteInterval,
+		defaultTimeout:      DefaultMaxExecuteDuration,
+		unregisteredDelay:   defaultUnregisteredDelay,
+		tasks:               make(map[string]TaskIFace),
+		aliases:             make(map[string]TaskIFace),
+		workerStatus:        make(map[int64]*atomicBool, concurrent),
+		workerQuit:          make(map[int64]chan struct{}),
+		events:              make(chan JobEvent, JobEventBufferSize),
+		dedicatedPools:      make(map[string]*dedicatedPool),
+		dedicatedWorkers:    make(map[int64]*dedicatedPool),
+		inWorkingMap:        make(map[string]*workingJob),
+		longRunningNotified: make(map[string]bool),
+		partitionInFlight:   make(map[string]map[string]int64),
+		taskInFlight:        make(map[string]int64),
+		latencies:           make(map[string]*latencyWindow),
+		limiters:            make(map[string]*rate.Limiter),
+		breakers:            make(map[string]*circuitBreaker),
+		lock:                sync.Mutex{},
+	}
+}
+
+// setComponentLogLevel 设置指定组件的最低日志级别：基于基础日志记录器派生带级别过滤的子日志记录器
+func (m *manager) setComponentLogLevel(component LogComponent, level zapcore.Level) error {
+	leveled := newLevelLogger(m.logger, level)
+
+	switch component {
+	case LogComponentLooper:
+		m.looperLogger = leveled
+	case LogComponentWorker:
+		m.workerLogger = leveled
+	case LogComponentShutdown:
+		m.shutdownLogger = leveled
+	default:
+		return fmt.Errorf("queue do not support log component: %s", component)
+	}
+	return nil
+}
+
+// setMetricsCollector 设置指标采集器，仅可在启动之前设置
+func (m *manager) setMetricsCollector(collector MetricsCollector) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.inStarted.isSet() {
+		return ErrQueueStarted
+	}
+	if collector == nil {
+		collector = nopMetrics{}
+	}
+
+	m.metrics = collector
+	return nil
+}
+
+// use 注册任务类执行中间件，仅可在启动之前注册
+func (m *manager) use(middlewares ...Middleware) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.inStarted.isSet() {
+		return ErrQueueStarted
+	}
+
+	m.middlewares = append(m.middlewares, middlewares...)
+	return nil
+}
+
+// cachedExecutor 已包装中间件的执行方法及其所属的任务类实例
+type cachedExecutor struct {
+	task    TaskIFace
+	execute ExecuteFunc
+}
+
+// executor 获取任务类已包装中间件的执行方法：每个任务类仅构建一次，先注册的中间件位于最外层
+// 同名任务类重新注册后，仍在执行旧任务类job的协程构建的执行方法不写入缓存，避免新任务类的job执行旧代码
+func (m *manager) executor(task TaskIFace) ExecuteFunc {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if cached, ok := m.executors[task.Name()]; ok && sameTask(cached.task, task) {
+		return cached.execute
+	}
+
+	execute := ExecuteFunc(task.Execute)
+	if typedTask, ok := task.(typedExecutor); ok {
+		execute = func(ctx context.Context, job *RawBody) error {
+			return typedTask.executeTyped(ctx, job, task)
+		}
+	} else if resultTask, ok := task.(ResultTask); ok {
+		execute = func(ctx context.Context, job *RawBody) error {
+			data, err := resultTask.ExecuteResult(ctx, job)
+			if err == nil {
+				storeResultToContext(ctx, data)
+			}
+			return err
+		}
+	} else if progressTask, ok := task.(ProgressAware); ok {
+		execute = func(ctx context.Context, job *RawBody) error {
+			return progressTask.ExecuteWithProgress(ctx, job, progressFromContext(ctx))
+		}
+	}
+	for i := len(m.middlewares) - 1; i >= 0; i-- {
+		execute = m.middlewares[i](execute)
+	}
+	if sameTask(m.tasks[task.Name()], task) {
+		m.executors[task.Name()] = cachedExecutor{task: task, execute: execute}
+	}
+	return execute
+}
+
+// sameTask 判断两个任务类是否为同一实例，任务类为不可比较的值类型时视为不同实例
+func sameTask(a, b TaskIFace) (same bool) {
+	if a == nil || b == nil {
+		return false
+	}
+	if reflect.TypeOf(a) != reflect.TypeOf(b) || !reflect.TypeOf(a).Comparable() {
+		return false
+	}
+	// 可比较的struct中含有不可比较值的interface字段时比较会panic
+	defer func() {
+		if recover() != nil {
+			same = false
+		}
+	}()
+	return a == b
+}
+
+// setConcurrent 设置并发worker数量，仅可在启动之前设置
+func (m *manager) setConcurrent(concurrent int64) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.inStarted.isSet() {
+		return ErrQueueStarted
 	}
+	if concurrent <= 0 || concurrent <= m.fastWorkers {
+		return fmt.Errorf("queue concurrent must be greater than 0 and fast workers %d", m.fastWorkers)
+	}
+
+	m.concurrent = concurrent
+	return nil
 }
 
 // bootstrapOne 脚手架辅助载入注册一个任务类
 func (m *manager) bootstrapOne(task TaskIFace) error {
 	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return m.registerLocked(task)
+}
+
+// registerLocked 注册一个任务类，调用方需持有锁
+// 任务类与旧名称映射map写时复制：looper持有的快照不会被修改，启动后注册无需暂停取出
+func (m *manager) registerLocked(task TaskIFace) error {
+	if _, exist := m.aliases[task.Name()]; exist {
+		return fmt.Errorf("queue task name %s already registered as alias", task.Name())
+	}
+	if typedTask, ok := task.(typedExecutor); ok {
+		if err := typedTask.checkTyped(task); err != nil {
+			return err
+		}
+	}
+	if scheduledTask, ok := task.(ScheduledTask); ok {
+		if _, err := parseSchedule(scheduledTask); err != nil {
+			return err
+		}
+	}
+
+	var aliases []string
+	if aliasTask, ok := task.(AliasTask); ok {
+		aliases = aliasTask.Aliases()
+	}
+	for _, alias := range aliases {
+		if _, exist := m.tasks[alias]; exist || alias == task.Name() {
+			return fmt.Errorf("queue task alias %s already registered as task name", alias)
+		}
+		if registered, exist := m.aliases[alias]; exist && registered.Name() != task.Name() {
+			return fmt.Errorf("queue task alias %s already registered by task %s", alias, registered.Name())
+		}
+	}
 
 	// log
 	m.logger.Debug(
 		"bootstrap",
-		zap.String("name", task.Name()),
-		zap.Int64("max_tries", task.MaxTries()),
-		zap.Int64("retry_interval", task.RetryInterval()),
+		field("name", task.Name()),
+		field("max_tries", task.MaxTries()),
+		field("retry_interval", task.RetryInterval()),
+		field("aliases", aliases),
 	)
 
-	m.tasks[task.Name()] = task
-	m.lock.Unlock()
+	tasks, taskAliases := copyTaskMap(m.tasks), copyTaskMap(m.aliases)
+	tasks[task.Name()] = task
+	for _, alias := range aliases {
+		taskAliases[alias] = task
+	}
+	m.tasks, m.aliases = tasks, taskAliases
+	delete(m.executors, task.Name())
+	m.notifyScheduleChanged()
+	if limiter := newTaskLimiter(task); limiter != nil {
+		m.limiters[task.Name()] = limiter
+	} else {
+		delete(m.limiters, task.Name())
+	}
+	if breaker := newCircuitBreaker(task); breaker != nil {
+		m.breakers[task.Name()] = breaker
+	} else {
+		delete(m.breakers, task.Name())
+	}
+
+	return nil
+}
+
+// deregister 注销一个任务类及其旧名称，可在启动后调用
+// 执行中的job继续执行至结束，队列中剩余的job不再被取出，已取出尚未执行的job按找不到任务类处理
+func (m *manager) deregister(name string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if _, exist := m.tasks[name]; !exist {
+		return fmt.Errorf("queue task %s do not bootstrap", name)
+	}
+
+	tasks, taskAliases := copyTaskMap(m.tasks), copyTaskMap(m.aliases)
+	delete(tasks, name)
+	for alias, task := range taskAliases {
+		if task.Name() == name {
+			delete(taskAliases, alias)
+		}
+	}
+	m.tasks, m.aliases = tasks, taskAliases
+	delete(m.executors, name)
+	delete(m.limiters, name)
+	delete(m.breakers, name)
+	m.notifyScheduleChanged()
 
+	m.logger.Info("queue.task.deregistered", field("name", name))
 	return nil
 }
 
+// taskMaps 获取已注册的任务类与旧名称映射map快照，写时复制保证返回的map不会再被修改，可不持有锁遍历
+func (m *manager) taskMaps() (tasks, aliases map[string]TaskIFace) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.tasks, m.aliases
+}
+
+// registeredTask 按任务类名称获取已注册的任务类，不匹配旧名称
+func (m *manager) registeredTask(name string) (task TaskIFace, ok bool) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	task, ok = m.tasks[name]
+	return task, ok
+}
+
+// copyTaskMap 复制任务类映射map
+func copyTaskMap(src map[string]TaskIFace) map[string]TaskIFace {
+	dst := make(map[string]TaskIFace, len(src)+1)
+	for name, task := range src {
+		dst[name] = task
+	}
+	return dst
+}
+
 // bootstrap 脚手架辅助载入注册多个任务类
 func (m *manager) bootstrap(tasks []TaskIFace) (err error) {
 	for _, job := range tasks {
@@ -100,290 +478,2175 @@ func (m *manager) start() (err error) {
 		return ErrQueueClosed
 	}
 
-	// 启动loop执行者循环调度
-	go m.startLooper()
+	// 没有已注册任务类时消费者空转，几乎总是忘记注册任务类导致：严格模式返回error，否则记录告警日志
+	if len(m.consumeQueueNames()) == 0 {
+		m.lock.Lock()
+		strict := m.strictStart
+		m.lock.Unlock()
+		if strict {
+			return ErrNoTasksRegistered
+		}
+		m.logger.Warn(ErrNoTasksRegistered.Error())
+	}
+
+	m.inStarted.setTrue()
+
+	// 启动loop执行者循环调度：多个looper均退出后再关闭job chan，保证仅关闭一次且关闭后不会再有投递
+	m.looperWg.Add(m.loopers)
+	for i := 0; i < m.loopers; i++ {
+		go m.startLooper(i)
+	}
+	go m.closeChannelAfterLoopersExited()
+
+	// 队列实现需由消费端晋升延迟任务时启动晋升协程
+	if promoter, ok := m.queue.(DelayedPromoter); ok {
+		m.producerWg.Add(1)
+		go m.startPromoter(promoter)
+	}
+
+	// 启动周期任务调度器：启动后注册的周期任务同样需要调度，故未注册周期任务时也启动
+	m.producerWg.Add(1)
+	go m.startScheduler()
 
 	// 并发启动多个消费worker进程
-	var i int64
-	for i = 0; i < m.concurrent; i++ {
-		go m.startWorker(i)
+	m.lock.Lock()
+	for m.nextWorkerID < m.concurrent {
+		m.spawnWorkerLocked()
 	}
+	// 专用worker池的worker在普通worker之外额外启动
+	m.spawnDedicatedWorkersLocked()
+	m.lock.Unlock()
 
 	return err
 }
 
+// startPromoter 启动延迟任务晋升协程：按扫描间隔将所有已注册队列中执行时刻已到的延迟任务晋升到待执行队列
+func (m *manager) startPromoter(promoter DelayedPromoter) {
+	defer m.producerWg.Done()
+
+	ticker := time.NewTicker(m.promoteInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.producerDone:
+			m.looperLogger.Info("shutdown, queue delayed promoter exited")
+			return
+		case <-ticker.C:
+			for _, name := range m.consumeQueueNames() {
+				count, err := promoter.PromoteDelayed(name)
+				if err != nil {
+					m.looperLogger.Error("queue.delayed.promote.failed", field("queue", name), field("error", err))
+					continue
+				}
+				if count > 0 {
+					m.looperLogger.Debug("queue.delayed.promoted", field("queue", name), field("count", count))
+				}
+			}
+		}
+	}
+}
+
+// consumeQueueNames 所有需消费的队列名称：已注册任务类名称、优先级子队列名称以及任务类旧名称
+func (m *manager) consumeQueueNames() []string {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	names := make([]string, 0, len(m.tasks)+len(m.aliases))
+	for name, task := range m.tasks {
+		for priority := 0; priority < priorityLevels(task); priority++ {
+			names = append(names, priorityQueueName(name, priority))
+		}
+	}
+	for alias := range m.aliases {
+		names = append(names, alias)
+	}
+	return names
+}
+
+// setLooperInterval 设置looper空闲休眠间隔范围，仅可在启动之前设置
+func (m *manager) setLooperInterval(min, max time.Duration) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.inStarted.isSet() {
+		return ErrQueueStarted
+	}
+	if min <= 0 || min >= max {
+		return fmt.Errorf("queue looper interval min must be greater than 0 and less than max")
+	}
+
+	m.looperIntervalMin = min
+	m.looperIntervalMax = max
+	return nil
+}
+
+// setPromoteInterval 设置延迟任务晋升扫描间隔，仅可在启动之前设置
+func (m *manager) setPromoteInterval(interval time.Duration) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.inStarted.isSet() {
+		return ErrQueueStarted
+	}
+	if interval <= 0 {
+		return fmt.Errorf("queue delayed promote interval must be greater than 0")
+	}
+
+	m.promoteInterval = interval
+	return nil
+}
+
+// looperState 单个looper协程的循环状态
+type looperState struct {
+	index          int             // looper序号
+	nonEmptyQueues map[string]bool // 最近一次取出到job的队列集合，用于判断队列由非空变为空
+	jitter         time.Duration   // 循环器抖动间隔
+	backpressured  bool            // 本轮是否因无空闲worker跳过了取出
+}
+
 // startLooper 启动队列进程looper，循环触发job消费
-func (m *manager) startLooper() {
+// @param index looper序号，多个looper时按队列名称哈希划分各自负责的队列
+func (m *manager) startLooper(index int) {
+	defer m.looperWg.Done()
+
+	state := &looperState{
+		index:          index,
+		nonEmptyQueues: make(map[string]bool),
+		jitter:         m.looperIntervalMin,
+	}
+
 	for {
 		select {
 		case <-m.getDoneChan():
-			m.logger.Info("shutdown, queue looper exited")
-			close(m.channel) // close job chan
+			m.looperLogger.Info("shutdown, queue looper exited", field("looper", index))
 			return
 		default:
-			m.looper() // continue loop all queue jobs
+			m.looper(state) // continue loop all queue jobs
 		}
 	}
 }
 
+// closeChannelAfterLoopersExited 所有looper退出后关闭job chan，worker消费完毕后随之退出
+// job chan仅由looper协程在 dispatch 中写入，等待所有looper退出后再关闭可保证关闭后不再有写入
+func (m *manager) closeChannelAfterLoopersExited() {
+	m.looperWg.Wait()
+
+	close(m.channel) // close job chan
+	if m.fastChannel != nil {
+		close(m.fastChannel)
+	}
+	m.closeDedicatedChannels()
+}
+
 // looper 轮询 && 速率控制所有队列的looper
-func (m *manager) looper() {
+func (m *manager) looper(state *looperState) {
+	// 先标记本轮取出进行中再检查暂停状态：reload 暂停后等待标记归零即可保证不再有looper遍历任务类
+	atomic.AddInt64(&m.loopersInPass, 1)
+
+	// 暂停中不取出job，执行中的job不受影响
+	if m.paused.isSet() {
+		atomic.AddInt64(&m.loopersInPass, -1)
+		m.looperSleep(m.looperJitter(state))
+		return
+	}
+
 	// map的range是无序的，无需再随机pop队列
 	// range本身就是随机的
+	passAt := time.Now()
 	needSleep := true
-	for name := range m.tasks {
-		if job, exist := m.queue.Pop(name); exist {
-			m.channel <- job // push job to worker for control process
+	tasks, aliases := m.taskMaps()
+	for name, task := range tasks {
+		if m.loopQueue(state, name, task) {
+			needSleep = false
+		}
+	}
+	// 任务类旧名称队列中的job继续由当前任务类消费
+	for alias, task := range aliases {
+		if m.loopQueue(state, alias, task) {
 			needSleep = false
 		}
 	}
+	atomic.AddInt64(&m.loopersInPass, -1)
+
+	// 因无空闲worker跳过了取出：短暂等待后即开始下一轮，避免worker空闲后仍需等待looper休眠结束
+	// 等待空闲worker说明worker不足，计入繁忙时长
+	if needSleep && state.backpressured {
+		state.backpressured = false
+		m.looperSleep(backpressurePollInterval)
+		atomic.AddInt64(&m.looperBusyNanos, int64(time.Since(passAt)))
+		return
+	}
+	atomic.AddInt64(&m.looperBusyNanos, int64(time.Since(passAt)))
 
 	// 所有队列都没job任务 looper随机休眠
 	if needSleep {
-		m.logger.Debug("no job pop, sleep for a while")
+		m.looperLogger.Debug("no job pop, sleep for a while", field("looper", state.index))
 
-		time.Sleep(m.looperJitter())
+		idleAt := time.Now()
+		m.looperSleep(m.looperJitter(state))
+		atomic.AddInt64(&m.looperIdleNanos, int64(time.Since(idleAt)))
 	}
 }
 
-// startWorker 启动队列进程工作者
-func (m *manager) startWorker(workerID int64) {
-	defer func() {
-		m.logger.Info(fmt.Sprintf("queue worker-%d exited", workerID), zap.Int64("worker_id", workerID))
-	}()
-
-	// started logger
-	m.logger.Info(fmt.Sprintf("queue worker-%d started", workerID), zap.Int64("worker_id", workerID))
+// looperSleep looper休眠指定时长，关闭信号到达时立即结束休眠
+func (m *manager) looperSleep(d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
 
-	// 阻塞消费job chan
-	for job := range m.channel {
-		m.runJob(job, workerID) // process run job
+	select {
+	case <-m.getDoneChan():
+	case <-timer.C:
 	}
 }
 
-// runJob 执行队列job，超时控制 && 尝试次数控制，执行结果控制
-func (m *manager) runJob(job JobIFace, workerID int64) {
-	// set worker is true
-	m.setWorkerStatus(workerID, true)
+// loopQueue 从单个队列取出job并投递给worker，返回是否取出到job
+func (m *manager) loopQueue(state *looperState, name string, task TaskIFace) (popped bool) {
+	// 关闭中不再取出新的job：本轮剩余队列直接跳过，looper随后退出
+	if !m.isLooperQueue(state.index, name) || m.shuttingDown() {
+		return false
+	}
+	// 无可接收该队列job的空闲worker：暂不取出，避免job取出后阻塞在投递上而延长保留时长
+	if !m.hasIdleWorker(name) {
+		state.backpressured = true
+		return false
+	}
+	// 任务类并发执行数已达上限、已无速率配额或已熔断：暂停取出，不视为队列变为空
+	if m.isTaskAtCapacity(task) || m.isTaskRateLimited(task) || !m.breakerAllow(task) {
+		return false
+	}
 
-	// step1、任务类执行捕获可能的panic
-	defer func() {
-		// set worker execute is false
-		m.setWorkerStatus(workerID, false)
+	// 按队列权重每轮连续取出多次，队列为空、并发已达上限或关闭中即停止
+	weight := m.taskWeight(task)
+	for i := int64(0); i < weight; i++ {
+		if i > 0 && (m.shuttingDown() || !m.hasIdleWorker(name) || m.isTaskAtCapacity(task) || m.isTaskRateLimited(task) || !m.breakerAllow(task)) {
+			break
+		}
+		batches := m.popBatches(name, task)
+		if len(batches) == 0 {
+			break
+		}
+		state.nonEmptyQueues[name] = true
+		for _, jobs := range batches {
+			m.metrics.JobPopped(name, len(jobs))
+			m.dispatch(name, jobs) // push job batch to worker for control process in sequence
+		}
+		popped = true
+	}
+	if popped {
+		return true
+	}
 
-		// delete in running map
-		delete(m.inWorkingMap, job.Payload().ID)
+	if state.nonEmptyQueues[name] {
+		// 队列由非空变为空：每次变为空仅触发一次
+		delete(state.nonEmptyQueues, name)
+		if m.onQueueEmpty != nil {
+			m.safeQueueCallback(name, func() { m.onQueueEmpty(name) })
+		}
+	}
+	return false
+}
 
-		// recovery if panic
-		if err := recover(); err != nil {
-			m.logger.Error(
-				"queue.execute.panic",
-				zap.StackSkip("stack", 2),
-				zap.String("queue", job.GetName()),
-				zap.Int64("worker_id", workerID),
-				zap.Any("payload", job.Payload()),
-				zap.Any("error", err),
+// safeQueueCallback 执行队列级别的回调：捕获回调中的panic并以队列名称记录日志，避免looper协程崩溃
+func (m *manager) safeQueueCallback(name string, callback func()) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			m.looperLogger.Error(
+				"queue.callback.panic",
+				field("stack", string(debug.Stack())),
+				field("queue", name),
+				field("panic", fmt.Sprintf("%v", recovered)),
 			)
-
-			var eErr error
-			switch t := err.(type) {
-			case error:
-				eErr = t
-			default:
-				eErr = fmt.Errorf("%s", t)
-			}
-
-			// panic: 检查任务尝试执行次数 & 标记失败状态
-			m.markJobAsFailedIfWillExceedMaxAttempts(job, eErr)
 		}
 	}()
+	callback()
+}
 
-	task, ok := m.tasks[job.GetName()]
-	if !ok {
-		return
+// deliveryMode 任务类投递语义，未实现 DeliveryModeTask 时为 AtLeastOnce
+func (m *manager) deliveryMode(task TaskIFace) DeliveryMode {
+	if modeTask, ok := task.(DeliveryModeTask); ok {
+		return modeTask.DeliveryMode()
 	}
+	return AtLeastOnce
+}
 
-	// step2、因为没有超时主动退出机制当任务执行超时仍在执行时标记再次延迟
-	if _, exist := m.inWorkingMap[job.Payload().ID]; exist {
-		m.logger.Warn(
-			ErrAbortForWaitingPrevJobFinish.Error(),
-			zap.String("queue", job.GetName()),
-			zap.Any("payload", job.Payload()),
-			zap.Time("pop_time", job.PopTime()),
-		)
+// hasIdleWorker 检查是否有可接收指定队列job的空闲worker：job chan有缓冲空间时视为可接收
+// 1、设置了专用worker池的队列job仅由其专用worker执行
+// 2、快速队列的job可由预留worker或普通worker执行，其他队列的job仅由普通worker执行
+// 3、空闲worker数量由worker自身原子计数，looper每轮检查无需持有锁遍历worker状态；专用worker池、快速队列均在启动前设置，启动后只读
+func (m *manager) hasIdleWorker(name string) bool {
+	if pool, ok := m.dedicatedPools[name]; ok {
+		return atomic.LoadInt64(&pool.idle) > 0
+	}
+	if len(m.channel) < cap(m.channel) {
+		return true
+	}
+	if atomic.LoadInt64(&m.idleWorkers) > 0 {
+		return true
+	}
+	return m.fastQueues[name] && atomic.LoadInt64(&m.idleFastWorkers) > 0
+}
 
-		// 当前任务作为延迟任务再次投递
-		// warning 当前正在执行的可能执行成功这样会导致一条任务多次被成功执行，需要任务类自主实现业务逻辑幂等
-		if payload, err := json.Marshal(job.Payload()); err == nil {
-			_ = job.Queue().Later(job.GetName(), time.Duration(job.Payload().RetryInterval)*time.Second, payload)
-		}
+// idleCounterLocked 获取worker对应的空闲worker计数：专用worker为其所属worker池的计数，调用方需持有锁
+func (m *manager) idleCounterLocked(workerID int64) *int64 {
+	if pool, ok := m.dedicatedWorkers[workerID]; ok {
+		return &pool.idle
+	}
+	if workerID < m.fastWorkers {
+		return &m.idleFastWorkers
+	}
+	return &m.idleWorkers
+}
 
-		// 触发记录可能失败日志的记录，便于回溯
-		m.recordFailedJob(job, ErrAbortForWaitingPrevJobFinish)
+// taskWeight 任务类调度权重，未实现 WeightedTask 或设置值小于1时为1
+func (m *manager) taskWeight(task TaskIFace) int64 {
+	if weightedTask, ok := task.(WeightedTask); ok && weightedTask.Weight() > 1 {
+		return weightedTask.Weight()
+	}
+	return 1
+}
 
-		return
+// isLooperQueue 检查队列是否由指定序号的looper负责：按队列名称哈希取模划分
+func (m *manager) isLooperQueue(index int, name string) bool {
+	if m.loopers <= 1 {
+		return true
 	}
+	return int(crc32.ChecksumIEEE([]byte(name))%uint32(m.loopers)) == index
+}
 
-	// set in running map
-	m.inWorkingMap[job.Payload().ID] = workerID
+// setLoopers 设置looper协程数量，仅可在启动之前设置
+func (m *manager) setLoopers(loopers int) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
 
-	// step3、检查任务尝试次数：超限标记任务失败后删除任务，未超限则执行
-	if m.markJobAsFailedIfAlreadyExceedsMaxAttempts(job) {
-		return
+	if m.inStarted.isSet() {
+		return ErrQueueStarted
+	}
+	if loopers <= 0 {
+		return fmt.Errorf("queue loopers must be greater than 0")
 	}
 
-	// step4、execute job task with timeout control
-	m.logger.Info(
-		textJobProcessing,
-		zap.String("queue", job.GetName()),
-		zap.Int64("worker_id", workerID),
-		zap.Any("payload", job.Payload()),
-	)
+	m.loopers = loopers
+	return nil
+}
 
-	// timeout context control
-	ctx, cancelFunc := context.WithTimeout(context.Background(), job.Timeout())
-	defer cancelFunc()
+// setQuietPeriod 设置优雅关闭的静默等待时长，仅可在启动之前设置
+func (m *manager) setQuietPeriod(period time.Duration) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
 
-	// goroutine execute task job
-	go func() {
-		err := task.Execute(ctx, job.Payload().RawBody())
-		if err == nil {
-			// step5、任务类执行成功：删除任务即可
-			m.logger.Info(
-				textJobProcessed,
-				zap.String("queue", job.GetName()),
-				zap.Int64("worker_id", workerID),
-				zap.Any("payload", job.Payload()),
-				zap.Duration("duration", time.Now().Sub(job.PopTime())),
-			)
-			_ = job.Delete()
-		} else {
-			// step6、任务类执行失败：依赖重试设置执行重试or最终执行失败处理
-			m.logger.Error(
-				textJobFailed,
-				zap.String("queue", job.GetName()),
-				zap.Int64("worker_id", workerID),
-				zap.Any("payload", job.Payload()),
-				zap.Duration("duration", time.Now().Sub(job.PopTime())),
-			)
-			m.markJobAsFailedIfWillExceedMaxAttempts(job, err)
+	if m.inStarted.isSet() {
+		return ErrQueueStarted
+	}
+	if period < 0 {
+		return fmt.Errorf("queue shutdown quiet period must not be less than 0")
+	}
+
+	m.quietPeriod = period
+	return nil
+}
+
+// setStrictStart 设置严格启动模式，仅可在启动之前设置
+func (m *manager) setStrictStart(strict bool) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.inStarted.isSet() {
+		return ErrQueueStarted
+	}
+
+	m.strictStart = strict
+	return nil
+}
+
+// setStuckWindow 设置worker卡死判定时长，仅可在启动之前设置
+func (m *manager) setStuckWindow(window time.Duration) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.inStarted.isSet() {
+		return ErrQueueStarted
+	}
+	if window < 0 {
+		return fmt.Errorf("queue stuck window must not be less than 0")
+	}
+
+	m.stuckWindow = window
+	return nil
+}
+
+// setReserveTimeout 设置保留超时时长，仅可在启动之前设置
+func (m *manager) setReserveTimeout(timeout time.Duration) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.inStarted.isSet() {
+		return ErrQueueStarted
+	}
+	if timeout < 0 {
+		return fmt.Errorf("queue reserve timeout must not be less than 0")
+	}
+
+	m.reserveTimeout = timeout
+	return nil
+}
+
+// setRetryJitter 设置重试延迟随机抖动百分比，仅可在启动之前设置
+func (m *manager) setRetryJitter(percent int) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.inStarted.isSet() {
+		return ErrQueueStarted
+	}
+	if percent < 0 || percent > 100 {
+		return fmt.Errorf("queue retry jitter percent must be between 0 and 100")
+	}
+
+	m.retryJitter = percent
+	return nil
+}
+
+// setChannelBuffer 设置looper投递job到worker的通道缓冲大小，仅可在启动之前设置
+func (m *manager) setChannelBuffer(size int) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.inStarted.isSet() {
+		return ErrQueueStarted
+	}
+	if size < 0 {
+		return fmt.Errorf("queue channel buffer must not be less than 0")
+	}
+
+	m.channel = make(chan []JobIFace, size)
+	return nil
+}
+
+// setPayloadCodec 设置job序列化器，底层驱动不支持该序列化格式时返回error，仅可在启动之前设置
+func (m *manager) setPayloadCodec(codec PayloadCodec) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.inStarted.isSet() {
+		return ErrQueueStarted
+	}
+	aware, ok := m.queue.(payloadCodecAware)
+	if !ok {
+		return fmt.Errorf("queue driver do not support custom payload codec")
+	}
+	if err := aware.setPayloadCodec(codec); err != nil {
+		return err
+	}
+
+	m.payloadCodec = codec
+	return nil
+}
+
+// marshalJob 使用job序列化器序列化job，用于再次投递、死信队列等由消费者写回底层驱动的场景
+func (m *manager) marshalJob(payload Payload) ([]byte, error) {
+	return payloadCodecOf(m.payloadCodec).Marshal(payload)
+}
+
+// setPopBatchSize 设置looper每次从单个队列取出的批数，仅可在启动之前设置
+func (m *manager) setPopBatchSize(size int) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.inStarted.isSet() {
+		return ErrQueueStarted
+	}
+	if size <= 0 {
+		return fmt.Errorf("queue pop batch size must be greater than 0")
+	}
+
+	m.popBatchSize = int64(size)
+	return nil
+}
+
+// spawnWorkerLocked 以新的workerID启动一个worker，调用方需持有锁
+// 为快速队列预留的worker不支持缩容，无退出信号chan
+func (m *manager) spawnWorkerLocked() {
+	workerID := m.nextWorkerID
+	m.nextWorkerID++
+
+	var quit chan struct{}
+	if workerID >= m.fastWorkers {
+		quit = make(chan struct{})
+		m.workerQuit[workerID] = quit
+	}
+	atomic.AddInt64(&m.liveWorkers, 1)
+	go m.startWorker(workerID, quit)
+}
+
+// scale 运行时调整worker数量：扩容以新的workerID启动worker，缩容通知workerID最大的普通worker执行完当前job后退出
+// 未启动时仅调整并发数
+func (m *manager) scale(concurrent int64) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.shuttingDown() {
+		return ErrQueueClosed
+	}
+	if concurrent <= 0 || concurrent <= m.fastWorkers {
+		return fmt.Errorf("queue concurrent must be greater than 0 and fast workers %d", m.fastWorkers)
+	}
+	if !m.inStarted.isSet() {
+		m.concurrent = concurrent
+		return nil
+	}
+
+	// 扩容
+	for i := m.concurrent; i < concurrent; i++ {
+		m.spawnWorkerLocked()
+	}
+
+	// 缩容：按workerID从大到小通知退出
+	if shrink := m.concurrent - concurrent; shrink > 0 {
+		ids := make([]int64, 0, len(m.workerQuit))
+		for id := range m.workerQuit {
+			ids = append(ids, id)
+		}
+		sort.Slice(ids, func(i, j int) bool { return ids[i] > ids[j] })
+		for _, id := range ids[:shrink] {
+			close(m.workerQuit[id])
+			delete(m.workerQuit, id)
 		}
-		cancelFunc()
+	}
+
+	m.workerLogger.Info("queue.workers.scaled", field("from", m.concurrent), field("to", concurrent))
+	m.concurrent = concurrent
+	return nil
+}
+
+// startWorker 启动队列进程工作者
+// @param workerID worker的ID
+// @param quit     退出信号chan，关闭后worker执行完当前job后退出；为快速队列预留的worker为nil
+func (m *manager) startWorker(workerID int64, quit <-chan struct{}) {
+	defer atomic.AddInt64(&m.liveWorkers, -1)
+	defer func() {
+		// 退出的worker不再参与worker状态检查
+		m.lock.Lock()
+		delete(m.workerStatus, workerID)
+		m.lock.Unlock()
+
+		m.workerLogger.Info(fmt.Sprintf("queue worker-%d exited", workerID), field("worker_id", workerID))
 	}()
 
+	// 启动的worker计为空闲，退出时移除
+	m.lock.Lock()
+	idle := m.idleCounterLocked(workerID)
+	m.lock.Unlock()
+	atomic.AddInt64(idle, 1)
+	defer atomic.AddInt64(idle, -1)
+
+	// started logger
+	m.workerLogger.Info(fmt.Sprintf("queue worker-%d started", workerID), field("worker_id", workerID))
+
+	// 专用worker仅消费其所属worker池的专用通道
+	if pool := m.dedicatedPoolOfWorker(workerID); pool != nil {
+		for jobs := range pool.channel {
+			m.runBatch(jobs, workerID)
+		}
+		return
+	}
+
+	// 为快速队列预留的worker仅消费快速队列通道
+	if workerID < m.fastWorkers {
+		for jobs := range m.fastChannel {
+			m.runBatch(jobs, workerID)
+		}
+		return
+	}
+
+	// 阻塞消费job chan：普通worker同时消费普通通道和快速队列通道，两者均关闭后退出
+	channel, fastChannel := m.channel, m.fastChannel
+	for channel != nil || fastChannel != nil {
+		select {
+		case jobs, ok := <-channel:
+			if !ok {
+				channel = nil
+				continue
+			}
+			m.runBatch(jobs, workerID)
+		case jobs, ok := <-fastChannel:
+			if !ok {
+				fastChannel = nil
+				continue
+			}
+			m.runBatch(jobs, workerID)
+		case <-quit:
+			// 缩容退出
+			return
+		}
+	}
+}
+
+// runBatch 执行一批job：同一批次的job由当前worker串行执行
+// 批次中某个job执行失败按该job自身的重试设置处理，不影响批次内剩余job继续执行
+func (m *manager) runBatch(jobs []JobIFace, workerID int64) {
+	defer atomic.AddInt64(&m.undoneBatches, -1)
+
+	m.setWorkerStatus(workerID, true)
+	for _, job := range jobs {
+		m.runJob(job, workerID) // process run job
+		m.releaseJobTaskSlot(job)
+	}
+	m.setWorkerStatus(workerID, false)
+}
+
+// dispatch 将取出的一批job投递给worker
+// 1、设置了专用worker池的队列job仅投递到其专用通道
+// 2、快速队列的job可由预留worker或普通worker任一空闲者执行，其他队列的job仅由普通worker执行
+// 3、投递可被关闭信号中断：此时可能已无worker接收，已取出的job立即放回队列，避免looper永久阻塞
+// 4、仅可在looper协程中调用，见 closeChannelAfterLoopersExited
+func (m *manager) dispatch(name string, jobs []JobIFace) {
+	channel := m.channel
+	var fastChannel chan []JobIFace // 非快速队列为nil，select永远不会选中
+	if pool, ok := m.dedicatedPools[name]; ok {
+		channel = pool.channel
+	} else if m.fastQueues[name] {
+		fastChannel = m.fastChannel
+	}
+
+	// 已收到关闭信号时不再投递：多个case同时就绪时select随机选择，需先行检查
+	done := m.getDoneChan()
 	select {
-	case <-ctx.Done():
-		// timeout to exit worker goroutine, but job may continue executed
-		m.markJobAsFailedIfWillExceedMaxAttempts(job, ctx.Err())
+	case <-done:
+		m.releaseUndispatched(jobs)
+		return
+	default:
+	}
+
+	atomic.AddInt64(&m.undoneBatches, 1)
+	select {
+	case channel <- jobs:
+	case fastChannel <- jobs:
+	case <-done:
+		m.releaseUndispatched(jobs)
+		atomic.AddInt64(&m.undoneBatches, -1)
+	}
+}
+
+// releaseUndispatched 将已取出但未能投递给worker的job立即放回队列
+// job未曾执行，按取出前的原始payload重新投递，不消耗尝试次数
+func (m *manager) releaseUndispatched(jobs []JobIFace) {
+	for _, job := range jobs {
+		if err := m.redeliver(job, 0); err != nil {
+			m.looperLogger.Error(
+				"queue.job.undispatched.release.failed",
+				field("queue", job.GetName()),
+				field("payload", job.Payload()),
+				field("error", err),
+			)
+			m.releaseJobTaskSlot(job)
+			continue
+		}
+		m.looperLogger.Info(
+			"queue.job.undispatched.released",
+			field("queue", job.GetName()),
+			field("payload", job.Payload()),
+		)
+		m.jobOutcome(job, JobReleased, JobOutcomeDetail{Err: ErrQueueClosed})
+		m.releaseJobTaskSlot(job)
+	}
+}
+
+// setFastQueues 设置快速队列以及为其预留的worker数量
+func (m *manager) setFastQueues(names []string, workers int64) error {
+	if workers <= 0 || workers >= m.concurrent {
+		return fmt.Errorf("queue fast workers must be greater than 0 and less than concurrent %d", m.concurrent)
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.fastQueues = make(map[string]bool, len(names))
+	for _, name := range names {
+		m.fastQueues[name] = true
+	}
+	m.fastWorkers = workers
+	m.fastChannel = make(chan []JobIFace)
+
+	return nil
+}
+
+// popBatches 按任务类批次大小从指定队列取出多批job，单次最多取出 popBatchSize 批
+// 未实现 BatchTask 的任务类每批仅取出1个job，队列为空时提前结束
+func (m *manager) popBatches(name string, task TaskIFace) (batches [][]JobIFace) {
+	size := int64(1)
+	if batchTask, ok := task.(BatchTask); ok && batchTask.BatchSize() > 1 {
+		size = batchTask.BatchSize()
+	}
+
+	// 任务类并发执行数、速率限制：先占用名额、配额再取出，未取出job的名额、配额归还
+	total, giveBack := m.acquireRateTokens(task, size*m.popBatchSize)
+	slots := m.acquireTaskSlots(task, total)
+	giveBack(total - slots)
+	jobs := m.popJobs(name, task, slots)
+	m.releaseTaskSlots(task, slots-int64(len(jobs)))
+	giveBack(slots - int64(len(jobs)))
+
+	for int64(len(jobs)) > size {
+		batches = append(batches, jobs[:size])
+		jobs = jobs[size:]
+	}
+	if len(jobs) > 0 {
+		batches = append(batches, jobs)
+	}
+	return batches
+}
+
+// popJobs 从指定队列取出最多n个job：队列实现了 BatchPopper 时一次取出，否则逐个取出直至队列为空
+func (m *manager) popJobs(name string, task TaskIFace, n int64) (jobs []JobIFace) {
+	if n <= 0 {
+		return nil
+	}
+	if levels := priorityLevels(task); levels > 1 && name == task.Name() {
+		return m.popPriorityJobs(name, levels, n)
+	}
+	if popper, ok := m.queue.(BatchPopper); ok && n > 1 {
+		jobs, err := popper.PopBatch(name, int(n))
+		if err != nil {
+			m.looperLogger.Error("queue.pop.batch.failed", field("queue", name), field("popped", len(jobs)), field("error", err))
+		}
+		return jobs
+	}
+
+	for i := int64(0); i < n; i++ {
+		job, exist := m.queue.Pop(name)
+		if !exist {
+			break
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs
+}
+
+// popPriorityJobs 按优先级从高到低从各优先级子队列取出最多n个job
+// 队列实现了 PriorityPopper 时使用其实现，否则按优先级从高到低逐个子队列调用 Pop
+func (m *manager) popPriorityJobs(name string, levels int, n int64) (jobs []JobIFace) {
+	popper, ok := m.queue.(PriorityPopper)
+	for int64(len(jobs)) < n {
+		var job JobIFace
+		var exist bool
+		if ok {
+			job, exist = popper.PopPriority(name, levels)
+		} else {
+			for priority := levels - 1; priority >= 0 && !exist; priority-- {
+				job, exist = m.queue.Pop(priorityQueueName(name, priority))
+			}
+		}
+		if !exist {
+			break
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs
+}
+
+// acquireTaskSlots 占用任务类并发执行名额，返回实际占用的名额数
+// 任务类未实现 ConcurrencyTask 或不限制时直接返回期望的名额数
+func (m *manager) acquireTaskSlots(task TaskIFace, size int64) int64 {
+	limitTask, ok := task.(ConcurrencyTask)
+	if !ok || limitTask.Concurrency() <= 0 {
+		return size
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	available := limitTask.Concurrency() - m.taskInFlight[task.Name()]
+	if available < size {
+		size = available
+	}
+	if size <= 0 {
+		return 0
+	}
+	m.taskInFlight[task.Name()] += size
+	return size
+}
+
+// isTaskAtCapacity 检查任务类并发执行数是否已达上限
+func (m *manager) isTaskAtCapacity(task TaskIFace) bool {
+	limitTask, ok := task.(ConcurrencyTask)
+	if !ok || limitTask.Concurrency() <= 0 {
+		return false
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.taskInFlight[task.Name()] >= limitTask.Concurrency()
+}
+
+// releaseTaskSlots 归还任务类并发执行名额
+func (m *manager) releaseTaskSlots(task TaskIFace, size int64) {
+	if size <= 0 {
+		return
+	}
+	if limitTask, ok := task.(ConcurrencyTask); !ok || limitTask.Concurrency() <= 0 {
+		return
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.taskInFlight[task.Name()] -= size
+	if m.taskInFlight[task.Name()] <= 0 {
+		delete(m.taskInFlight, task.Name())
+	}
+}
+
+// releaseJobTaskSlot 归还job所属任务类的1个并发执行名额
+func (m *manager) releaseJobTaskSlot(job JobIFace) {
+	if task, ok := m.taskByName(job.GetName()); ok {
+		m.releaseTaskSlots(task, 1)
+	}
+}
+
+// runJob 执行队列job，超时控制 && 尝试次数控制，执行结果控制
+func (m *manager) runJob(job JobIFace, workerID int64) {
+	var working *workingJob
+
+	// step1、任务类执行捕获可能的panic
+	defer func() {
+		// delete in running map：仅删除本次执行写入的记录，避免重复job误删仍在执行中的同ID job记录
+		// 执行时长告警标记在job离开时一并清理（含未写入执行中记录即返回的job），仍有同ID job执行中时保留
+		m.lock.Lock()
+		if working != nil && m.inWorkingMap[job.Payload().ID] == working {
+			delete(m.inWorkingMap, job.Payload().ID)
+		}
+		if _, running := m.inWorkingMap[job.Payload().ID]; !running {
+			delete(m.longRunningNotified, job.Payload().ID)
+		}
+		m.lock.Unlock()
+
+		// recovery if panic
+		if recovered := recover(); recovered != nil {
+			// panic: 检查任务尝试执行次数 & 标记失败状态
+			m.handleExecuteFailure(working, job, m.handlePanic(job, workerID, recovered))
+		}
+	}()
+
+	m.emitJobEvent(EventJobPopped, job, workerID, nil)
+
+	task, ok := m.taskByName(job.GetName())
+	if !ok {
+		m.handleUnregisteredJob(job)
+		return
+	}
+	// 每个job仅生成一次日志记录器，本次执行的后续日志均复用
+	job = &loggedJob{JobIFace: job, logger: m.newJobLogger(task, job)}
+	m.checkDeliveryDrift(job)
+
+	// step1.1、去重窗口内已执行成功的job被底层驱动再次投递：直接删除不再执行
+	if m.isRecentlyCompleted(job) {
+		m.jobLogger(job).Warn(
+			ErrJobRecentlyCompleted.Error(),
+			field("queue", job.GetName()),
+			field("worker_id", workerID),
+			field("payload", job.Payload()),
+		)
+		_ = job.Delete()
+		m.jobOutcome(job, JobDropped, JobOutcomeDetail{Err: ErrJobRecentlyCompleted})
+		m.emitJobEvent(EventJobDeleted, job, workerID, nil)
+		return
+	}
+
+	// step2、超时仅取消ctx无法强制退出执行中的任务类，超时后仍在执行时按策略处理本次取出的重复job
+	// 执行中job记录由多个执行协程以及状态查询并发读写，均需持有锁；检查与写入在同一临界区内完成，同ID的job仅一个可执行
+	current := &workingJob{workerID: workerID, job: job, task: task}
+	for {
+		m.lock.Lock()
+		_, exist := m.inWorkingMap[job.Payload().ID]
+		if !exist {
+			// set in running map
+			m.inWorkingMap[job.Payload().ID] = current
+		}
+		m.lock.Unlock()
+		if !exist {
+			break
+		}
+		// 等待执行中的job结束后可能已被另一个同ID的job抢先写入，再次按重复job处理
+		if !m.handleDuplicateJob(task, job) {
+			return
+		}
+	}
+	working = current
+
+	// step3、检查任务尝试次数：超限标记任务失败后删除任务，未超限则执行
+	if m.markJobAsFailedIfAlreadyExceedsMaxAttempts(job) {
+		return
+	}
+
+	// step3.0、解码任务参数：解码失败重试也无法恢复，直接按最终失败处理
+	body, err := m.rawBody(job)
+	if err != nil {
+		m.failJob(job, err)
 		return
 	}
+
+	// step3.0.1、任务类校验job参数：参数无效重试也无法恢复，直接按最终失败处理
+	if validatableTask, ok := task.(ValidatableTask); ok {
+		if err := validatableTask.Validate(body); err != nil {
+			m.jobLogger(job).Error(
+				textJobInvalid,
+				field("queue", job.GetName()),
+				field("worker_id", workerID),
+				field("payload", job.Payload()),
+				field("error", err),
+			)
+			m.failJob(job, fmt.Errorf("%w: %s", ErrPayloadInvalid, err.Error()))
+			return
+		}
+	}
+
+	// step3.1、任务类判断job是否仍需执行：无需执行则删除job，不视为失败
+	if shouldRunTask, ok := task.(ShouldRunTask); ok {
+		shouldRun, err := shouldRunTask.ShouldRun(body)
+		if err != nil {
+			m.jobLogger(job).Error(
+				textJobFailed,
+				field("queue", job.GetName()),
+				field("worker_id", workerID),
+				field("payload", job.Payload()),
+				field("error", err),
+			)
+			m.markJobAsFailedIfWillExceedMaxAttempts(job, err)
+			return
+		}
+		if !shouldRun {
+			m.jobLogger(job).Info(
+				textJobSkipped,
+				field("queue", job.GetName()),
+				field("worker_id", workerID),
+				field("payload", job.Payload()),
+			)
+			_ = job.Delete()
+			m.jobOutcome(job, JobSkipped, JobOutcomeDetail{})
+			m.emitJobEvent(EventJobDeleted, job, workerID, nil)
+			return
+		}
+	}
+
+	// step3.2、分区并发控制：分区执行中的job数已达上限则稍后再次投递，不消耗尝试次数
+	if releasePartition, acquired := m.acquirePartition(task, job, body); acquired {
+		defer releasePartition()
+	} else {
+		m.jobLogger(job).Debug(
+			"queue.partition.at.capacity",
+			field("queue", job.GetName()),
+			field("payload", job.Payload()),
+		)
+		if m.redeliver(job, partitionRedeliverDelay) == nil {
+			m.jobOutcome(job, JobRedelivered, JobOutcomeDetail{Delay: partitionRedeliverDelay, Err: ErrPartitionAtCapacity})
+		}
+		return
+	}
+
+	// step3.3、全局执行并发控制：与worker数量无关的执行名额限制
+	if releaseExecute, acquired := m.acquireExecute(); acquired {
+		defer releaseExecute()
+	} else {
+		m.jobLogger(job).Debug(
+			ErrExecuteLimitReached.Error(),
+			field("queue", job.GetName()),
+			field("payload", job.Payload()),
+		)
+		if m.redeliver(job, executeLimitRedeliverDelay) == nil {
+			m.jobOutcome(job, JobRedelivered, JobOutcomeDetail{Delay: executeLimitRedeliverDelay, Err: ErrExecuteLimitReached})
+		}
+		return
+	}
+
+	// step3.4、至多一次投递语义：执行前删除job，此后不再重试或再次投递
+	if m.deliveryMode(task) == AtMostOnce {
+		_ = job.Delete()
+		m.emitJobEvent(EventJobDeleted, job, workerID, nil)
+	}
+
+	// step4、execute job task with timeout control
+	m.jobLogger(job).Info(
+		textJobProcessing,
+		field("queue", job.GetName()),
+		field("worker_id", workerID),
+		field("payload", job.Payload()),
+	)
+
+	// timeout context control
+	result := &jobResult{}
+	ctx := withJobInfo(context.Background(), workerID, job)
+	ctx, cancelFunc := context.WithTimeout(withResult(m.withProgress(ctx, job), result), m.jobTimeout(job))
+	defer cancelFunc()
+	m.lock.Lock()
+	working.cancel = cancelFunc
+	m.lock.Unlock()
+
+	// goroutine execute task job：执行结果通过chan回传，任务类执行导致的panic在执行协程内捕获转换为error
+	working.transit(workingPreparing, workingExecuting)
+	m.emitJobEvent(EventJobStarted, job, workerID, nil)
+	done := make(chan error, 1)
+	execute := m.executor(task)
+	go func() {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				done <- m.handlePanic(job, workerID, recovered)
+			}
+		}()
+		done <- execute(ctx, body)
+	}()
+
+	select {
+	case err := <-done:
+		// 强制关闭时job已放回队列：不再处理本次执行结果，避免重复放回
+		if !m.settleWorking(working) {
+			return
+		}
+		// 任务类要求稍后再执行：重新投递，不视为执行失败
+		var reschedule *RescheduleError
+		if errors.As(err, &reschedule) {
+			m.rescheduleJob(job, workerID, reschedule.After)
+			return
+		}
+		// 任务类因ctx超时取消而返回error：统一视为执行超时；手动取消的job执行失败统一视为已取消
+		if err != nil && ctx.Err() == context.DeadlineExceeded {
+			err = m.handleTimeout(task, job)
+		} else if err != nil && working.canceled.isSet() {
+			err = ErrJobCanceled
+		}
+		duration := time.Now().Sub(job.PopTime())
+		m.metrics.JobExecuted(jobQueueName(job), duration, err)
+		m.breakerRecord(task, err)
+		if err == nil {
+			// step5、任务类执行成功：删除任务即可
+			m.jobLogger(job).Info(
+				textJobProcessed,
+				field("queue", job.GetName()),
+				field("worker_id", workerID),
+				field("payload", job.Payload()),
+				field("duration", duration),
+			)
+			m.recordLatency(jobQueueName(job), duration)
+			m.saveResult(job, result)
+			m.markCompleted(job)
+			_ = job.Delete()
+			m.jobOutcome(job, JobProcessed, JobOutcomeDetail{})
+			m.emitJobEvent(EventJobSucceeded, job, workerID, nil)
+			m.emitJobEvent(EventJobDeleted, job, workerID, nil)
+			if successAware, ok := task.(SuccessAware); ok {
+				m.safeCallback(job, func() { successAware.OnSuccess(*job.Payload()) })
+			}
+		} else {
+			// step6、任务类执行失败：依赖重试设置执行重试or最终执行失败处理
+			m.jobLogger(job).Error(
+				textJobFailed,
+				field("queue", job.GetName()),
+				field("worker_id", workerID),
+				field("payload", job.Payload()),
+				field("duration", duration),
+				field("error", err),
+			)
+			m.handleExecuteFailure(working, job, err)
+		}
+	case <-ctx.Done():
+		// timeout to exit worker goroutine, but job may continue executed
+		if !m.settleWorking(working) {
+			return
+		}
+		err := ErrJobCanceled
+		if !working.canceled.isSet() {
+			err = m.handleTimeout(task, job)
+		}
+		m.metrics.JobExecuted(jobQueueName(job), time.Now().Sub(job.PopTime()), err)
+		m.breakerRecord(task, err)
+		m.handleExecuteFailure(working, job, err)
+	}
+}
+
+// handleExecuteFailure 处理job执行失败：至多一次投递语义的job已在执行前删除，直接按最终失败处理
+// 手动取消的job不再重试直接按最终失败处理，否则依据重试设置处理
+func (m *manager) handleExecuteFailure(working *workingJob, job JobIFace, err error) {
+	if working != nil && m.deliveryMode(working.task) == AtMostOnce && job.IsDeleted() {
+		job.MarkAsFailed()
+		m.pushDeadLetter(job, err)
+		m.notifyJobFailed(job, err)
+		return
+	}
+	if errors.Is(err, ErrJobCanceled) || errors.Is(err, ErrPayloadInvalid) {
+		m.failJob(job, err)
+		return
+	}
+	m.markJobAsFailedIfWillExceedMaxAttempts(job, err)
+}
+
+// cancelJob 取消当前实例执行中的指定job：取消其执行ctx，job不存在或尚未开始执行时返回false
+func (m *manager) cancelJob(jobID string) bool {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	working, ok := m.inWorkingMap[jobID]
+	if !ok || working.cancel == nil {
+		return false
+	}
+	working.canceled.setTrue()
+	working.cancel()
+
+	m.workerLogger.Warn(
+		"queue.job.canceled",
+		field("queue", working.job.GetName()),
+		field("worker_id", working.workerID),
+		field("payload", working.job.Payload()),
+	)
+	return true
+}
+
+// rescheduleJob 按任务类要求将job作为延迟任务重新投递，不消耗尝试次数
+func (m *manager) rescheduleJob(job JobIFace, workerID int64, delay time.Duration) {
+	if err := m.redeliver(job, delay); err != nil {
+		// 重新投递失败时job仍在保留队列中，保留超时后再次执行
+		m.jobLogger(job).Error(
+			"queue.job.reschedule.failed",
+			field("queue", job.GetName()),
+			field("worker_id", workerID),
+			field("payload", job.Payload()),
+			field("error", err),
+		)
+		return
+	}
+	m.jobLogger(job).Info(
+		textJobDeferred,
+		field("queue", job.GetName()),
+		field("worker_id", workerID),
+		field("payload", job.Payload()),
+		field("delay", delay),
+	)
+	m.jobOutcome(job, JobRedelivered, JobOutcomeDetail{Delay: delay})
+}
+
+// settleWorking 执行协程落定job处理结果，返回false表示job已在强制关闭时放回队列
+func (m *manager) settleWorking(working *workingJob) bool {
+	if working.transit(workingExecuting, workingSettled) {
+		return true
+	}
+	m.jobLogger(working.job).Info(
+		textJobRequeued,
+		field("queue", working.job.GetName()),
+		field("worker_id", working.workerID),
+		field("payload", working.job.Payload()),
+	)
+	return false
+}
+
+// jobTimeout job执行超时时长：超时时长为0（例如任务类超时时长不足1秒）时使用默认超时时长，设置了上限时不超过上限
+func (m *manager) jobTimeout(job JobIFace) time.Duration {
+	timeout := job.Timeout()
+	if timeout <= 0 {
+		timeout = m.defaultTimeout
+	}
+	if m.maxTimeout > 0 && timeout > m.maxTimeout {
+		timeout = m.maxTimeout
+	}
+	return timeout
+}
+
+// setJobTimeout 设置job默认超时时长以及超时时长上限，仅可在启动之前设置
+func (m *manager) setJobTimeout(defaultTimeout, maxTimeout time.Duration) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.inStarted.isSet() {
+		return ErrQueueStarted
+	}
+	if defaultTimeout <= 0 {
+		return fmt.Errorf("queue default job timeout must be greater than 0")
+	}
+	if maxTimeout < 0 || (maxTimeout > 0 && defaultTimeout > maxTimeout) {
+		return fmt.Errorf("queue max job timeout must be 0 or not less than default job timeout")
+	}
+
+	m.defaultTimeout = defaultTimeout
+	m.maxTimeout = maxTimeout
+	return nil
+}
+
+// setMaxExecuteDuration 设置job最大执行时长即默认超时时长，保留已设置的超时时长上限，仅可在启动之前设置
+func (m *manager) setMaxExecuteDuration(d time.Duration) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.inStarted.isSet() {
+		return ErrQueueStarted
+	}
+	if d <= 0 {
+		return fmt.Errorf("queue max execute duration must be greater than 0")
+	}
+	if m.maxTimeout > 0 && d > m.maxTimeout {
+		return fmt.Errorf("queue max execute duration must not be greater than max job timeout")
+	}
+
+	m.defaultTimeout = d
+	return nil
+}
+
+// handleTimeout job执行超时处理：记录日志并触发任务类的超时回调，返回超时错误
+func (m *manager) handleTimeout(task TaskIFace, job JobIFace) error {
+	m.jobLogger(job).Warn(
+		ErrJobTimeout.Error(),
+		field("queue", job.GetName()),
+		field("payload", job.Payload()),
+		field("timeout", m.jobTimeout(job)),
+	)
+
+	if timeoutTask, ok := task.(TimeoutTask); ok {
+		m.safeCallback(job, func() { timeoutTask.OnTimeout(*job.Payload()) })
+	}
+	return ErrJobTimeout
+}
+
+// handlePanic 将捕获到的panic值规范化为error并记录日志
+// 设置了 PanicNormalizer 则使用其转换，否则error类型的panic值原样返回，其他类型使用%v格式化
+func (m *manager) handlePanic(job JobIFace, workerID int64, recovered interface{}) error {
+	normalizer := m.panicNormalizer
+	if normalizer == nil {
+		normalizer = defaultPanicNormalizer
+	}
+	err := normalizer(recovered)
+	stack := panicStack()
+
+	m.jobLogger(job).Error(
+		"queue.execute.panic",
+		field("stack", string(stack)),
+		field("queue", job.GetName()),
+		field("worker_id", workerID),
+		field("payload", job.Payload()),
+		field("panic_type", fmt.Sprintf("%T", recovered)),
+		field("error", err),
+	)
+
+	if m.panicHandler != nil {
+		m.safeCallback(job, func() { m.panicHandler(job, recovered, stack) })
+	}
+
+	return err
+}
+
+// panicStack 获取当前协程的完整调用栈，需在recover所在的defer中调用以包含panic发生处
+func panicStack() []byte {
+	buf := make([]byte, 4096)
+	for {
+		n := runtime.Stack(buf, false)
+		if n < len(buf) {
+			return buf[:n]
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
+
+// defaultPanicNormalizer 默认的panic值规范化方法
+func defaultPanicNormalizer(recovered interface{}) error {
+	if err, ok := recovered.(error); ok {
+		return err
+	}
+	return fmt.Errorf("%v", recovered)
+}
+
+// checkDeliveryDrift 检查job投递延迟：首次取出时刻与计划可被执行时刻的差值
+// 仅检查首次取出执行的job，重试、再次投递的job计划时刻已变化；未记录计划时刻的旧job不检查
+func (m *manager) checkDeliveryDrift(job JobIFace) {
+	if job.Attempts() != 1 || job.Payload().AvailableAt <= 0 {
+		return
+	}
+
+	latency := job.PopTime().Sub(time.Unix(job.Payload().AvailableAt, 0))
+	if latency < 0 {
+		latency = 0
+	}
+	if collector, ok := m.metrics.(DeliveryLatencyCollector); ok {
+		collector.JobDeliveryLatency(jobQueueName(job), latency)
+	}
+	if m.driftThreshold > 0 && latency > m.driftThreshold {
+		m.jobLogger(job).Warn(
+			"queue.job.delivery.drift",
+			field("queue", job.GetName()),
+			field("payload", job.Payload()),
+			field("latency", latency),
+			field("threshold", m.driftThreshold),
+		)
+	}
+}
+
+// handleUnregisteredJob 处理找不到任务类的job，例如新增队列的消费者尚未全部完成部署
+// 1、稍后再次投递且不消耗尝试次数，再次投递次数记录在job元数据头中
+// 2、设置了最多再次投递次数时，超过后按最终失败处理进入死信队列、失败任务处理器
+func (m *manager) handleUnregisteredJob(job JobIFace) {
+	payload := *job.Payload()
+	retries, _ := strconv.ParseInt(payload.Headers[unregisteredHeader], 10, 64)
+
+	m.workerLogger.Warn(
+		ErrTaskNotRegistered.Error(),
+		field("queue", job.GetName()),
+		field("payload", job.Payload()),
+		field("retries", retries),
+	)
+
+	if m.unregisteredMax > 0 && retries >= m.unregisteredMax {
+		m.failJob(job, ErrTaskNotRegistered)
+		return
+	}
+
+	headers := make(map[string]string, len(payload.Headers)+1)
+	for key, value := range payload.Headers {
+		headers[key] = value
+	}
+	headers[unregisteredHeader] = strconv.FormatInt(retries+1, 10)
+	payload.Headers = headers
+	payload.AvailableAt = time.Now().Add(m.unregisteredDelay).Unix()
+
+	body, err := m.marshalJob(payload)
+	if err == nil {
+		err = job.Queue().Later(job.GetName(), m.unregisteredDelay, body)
+	}
+	if err == nil {
+		err = job.Delete()
+	}
+	if err != nil {
+		m.workerLogger.Error(
+			"queue.unregistered.redeliver.failed",
+			field("queue", job.GetName()),
+			field("payload", job.Payload()),
+			field("error", err),
+		)
+		return
+	}
+	m.jobOutcome(job, JobRedelivered, JobOutcomeDetail{Delay: m.unregisteredDelay, Err: ErrTaskNotRegistered})
+}
+
+// setUnregisteredPolicy 设置找不到任务类的job的处理策略，仅可在启动之前设置
+func (m *manager) setUnregisteredPolicy(delay time.Duration, maxRetries int64) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.inStarted.isSet() {
+		return ErrQueueStarted
+	}
+	if delay < 0 || maxRetries < 0 {
+		return fmt.Errorf("queue unregistered job delay and max retries must not be negative")
+	}
+
+	m.unregisteredDelay = delay
+	m.unregisteredMax = maxRetries
+	return nil
+}
+
+// handleDuplicateJob 按执行中重复job处理策略处理本次取出的job
+// 返回true表示本次job可继续执行，返回false表示本次job已处理完毕无需执行
+func (m *manager) handleDuplicateJob(task TaskIFace, job JobIFace) (canContinue bool) {
+	m.jobLogger(job).Warn(
+		ErrAbortForWaitingPrevJobFinish.Error(),
+		field("queue", job.GetName()),
+		field("payload", job.Payload()),
+		field("pop_time", job.PopTime()),
+	)
+	if duplicateAware, ok := task.(DuplicateAware); ok {
+		m.safeCallback(job, func() { duplicateAware.OnDuplicateInFlight(*job.Payload()) })
+	}
+
+	action := DuplicateRedeliver
+	if m.duplicatePolicy != nil {
+		action = m.duplicatePolicy(job)
+	}
+
+	switch action {
+	case DuplicateDrop:
+		// 丢弃本次job：从保留队列删除，执行中的job结果即为最终结果
+		_ = job.Delete()
+		m.jobOutcome(job, JobDropped, JobOutcomeDetail{Err: ErrAbortForWaitingPrevJobFinish})
+		m.emitJobEvent(EventJobDeleted, job, -1, nil)
+		return false
+	case DuplicateWait:
+		// 等待执行中的job结束后继续执行本次job，等待超时则按再次投递处理
+		if m.waitPrevJobFinish(job) {
+			return true
+		}
+	}
+
+	// 当前任务作为延迟任务再次投递
+	// warning 当前正在执行的可能执行成功这样会导致一条任务多次被成功执行，需要任务类自主实现业务逻辑幂等
+	if payload, err := m.marshalJob(*job.Payload()); err == nil {
+		delay := time.Duration(job.Payload().RetryInterval) * time.Second
+		if m.reserveTimeout > 0 {
+			delay = m.reserveTimeout
+		}
+		if job.Queue().Later(job.GetName(), delay, payload) == nil {
+			m.jobOutcome(job, JobRedelivered, JobOutcomeDetail{Delay: delay, Err: ErrAbortForWaitingPrevJobFinish})
+		}
+	}
+
+	// 触发记录可能失败日志的记录，便于回溯
+	m.recordFailedJob(job, ErrAbortForWaitingPrevJobFinish)
+
+	return false
+}
+
+// waitPrevJobFinish 阻塞等待同ID执行中的job结束，最长等待job超时时长，设置了保留超时时长时最长等待保留超时时长
+// 执行中的job在等待时长内结束返回true，否则返回false
+func (m *manager) waitPrevJobFinish(job JobIFace) (finished bool) {
+	wait := m.jobTimeout(job)
+	if m.reserveTimeout > 0 {
+		wait = m.reserveTimeout
+	}
+	deadline := time.Now().Add(wait)
+	for time.Now().Before(deadline) {
+		m.lock.Lock()
+		_, exist := m.inWorkingMap[job.Payload().ID]
+		m.lock.Unlock()
+		if !exist {
+			return true
+		}
+		time.Sleep(shutdownPollIntervalMax)
+	}
+	return false
+}
+
+// acquirePartition 获取job所属分区的执行名额
+// 任务类未实现 PartitionTask 时不做分区控制直接返回获取成功；获取成功时返回的释放方法需在job执行结束后调用
+func (m *manager) acquirePartition(task TaskIFace, job JobIFace, body *RawBody) (release func(), acquired bool) {
+	partitionTask, ok := task.(PartitionTask)
+	if !ok {
+		return func() {}, true
+	}
+
+	name := jobQueueName(job)
+	key := partitionTask.PartitionKey(body)
+	limit := int64(1)
+	if limitTask, ok := task.(PartitionConcurrencyTask); ok && limitTask.PartitionConcurrency() > 1 {
+		limit = limitTask.PartitionConcurrency()
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.partitionInFlight[name] == nil {
+		m.partitionInFlight[name] = make(map[string]int64)
+	}
+	if m.partitionInFlight[name][key] >= limit {
+		return nil, false
+	}
+	m.partitionInFlight[name][key]++
+
+	return func() {
+		m.lock.Lock()
+		defer m.lock.Unlock()
+
+		m.partitionInFlight[name][key]--
+		if m.partitionInFlight[name][key] <= 0 {
+			delete(m.partitionInFlight[name], key)
+		}
+	}, true
+}
+
+// acquireExecute 获取全局执行名额
+// 未设置全局执行并发数时直接返回获取成功；获取成功时返回的释放方法需在job执行结束后调用
+func (m *manager) acquireExecute() (release func(), acquired bool) {
+	if m.executeSemaphore == nil {
+		return func() {}, true
+	}
+
+	release = func() { <-m.executeSemaphore }
+	if m.executeLimitAction == ExecuteLimitWait {
+		m.executeSemaphore <- struct{}{}
+		return release, true
+	}
+
+	select {
+	case m.executeSemaphore <- struct{}{}:
+		return release, true
+	default:
+		return nil, false
+	}
+}
+
+// setExecuteLimit 设置全局执行并发数，仅可在启动之前设置
+func (m *manager) setExecuteLimit(limit int64, action ExecuteLimitAction) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.inStarted.isSet() {
+		return ErrQueueStarted
+	}
+	if limit <= 0 {
+		return fmt.Errorf("queue execute limit must be greater than 0")
+	}
+
+	m.executeSemaphore = make(chan struct{}, limit)
+	m.executeLimitAction = action
+	return nil
+}
+
+// executeInFlight 全局执行并发信号量占用情况，未设置全局执行并发数时limit为0
+func (m *manager) executeInFlight() (inFlight, limit int64) {
+	if m.executeSemaphore == nil {
+		return 0, 0
+	}
+	return int64(len(m.executeSemaphore)), int64(cap(m.executeSemaphore))
+}
+
+// partitionInFlightSnapshot 各队列各分区执行中的job数量快照
+func (m *manager) partitionInFlightSnapshot() map[string]map[string]int64 {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	snapshot := make(map[string]map[string]int64, len(m.partitionInFlight))
+	for name, partitions := range m.partitionInFlight {
+		snapshot[name] = make(map[string]int64, len(partitions))
+		for key, count := range partitions {
+			snapshot[name][key] = count
+		}
+	}
+	return snapshot
+}
+
+// redeliver 将job按取出前的原始payload作为延迟任务重新投递，并从保留队列删除本次取出的job
+// 与 Release 不同，重新投递不消耗job的尝试次数
+func (m *manager) redeliver(job JobIFace, delay time.Duration) error {
+	redelivered := *job.Payload()
+	redelivered.AvailableAt = time.Now().Add(delay).Unix()
+	payload, err := m.marshalJob(redelivered)
+	if err != nil {
+		return err
+	}
+	if err = job.Queue().Later(job.GetName(), delay, payload); err != nil {
+		return err
+	}
+	// 至多一次投递语义的job已在执行前删除
+	if job.IsDeleted() {
+		return nil
+	}
+	return job.Delete()
+}
+
+// looperJitter looper循环器间隔抖动
+func (m *manager) looperJitter(state *looperState) time.Duration {
+	step := int(m.looperIntervalMin / 3)
+	if step <= 0 {
+		step = 1
+	}
+
+	state.jitter = state.jitter + time.Duration(rand.Intn(step))
+	if state.jitter > m.looperIntervalMax {
+		state.jitter = m.looperIntervalMin
+	}
+
+	return state.jitter
+}
+
+// checkLongRunning 执行时长检查：执行时长超过超时时长则记录日志并触发回调
+// 同一job单次取出执行期间仅触发一次，避免多处检查重复告警
+func (m *manager) checkLongRunning(job JobIFace) {
+	elapsed := time.Now().Sub(job.PopTime())
+	if elapsed < m.jobTimeout(job) {
+		return
+	}
+
+	m.lock.Lock()
+	notified := m.longRunningNotified[job.Payload().ID]
+	m.longRunningNotified[job.Payload().ID] = true
+	m.lock.Unlock()
+	if notified {
+		return
+	}
+
+	m.jobLogger(job).Warn(
+		textJobTooLong,
+		field("queue", job.GetName()),
+		field("payload", job.Payload()),
+		field("pop_time", job.PopTime()),
+		field("elapsed", elapsed),
+	)
+
+	if m.onLongRunning != nil {
+		m.safeCallback(job, func() { m.onLongRunning(job, elapsed) })
+	}
+}
+
+// markJobAsFailedIfAlreadyExceedsMaxAttempts job执行`之前`检测尝试次数是否超限
+// 1、如果超限则方法体内部清理任务并返回true，表示该job需要停止执行
+// 2、如果未超限则返回false
+func (m *manager) markJobAsFailedIfAlreadyExceedsMaxAttempts(job JobIFace) (needSop bool) {
+	// step1、执行时长检查，持续执行超过设置的超时时长则记录日志
+	m.checkLongRunning(job)
+
+	// step2、检查job是否已过期：超过任务类设置的最长有效时长即使仍有剩余尝试次数也不再执行
+	if m.isJobExpired(job) {
+		m.failJob(job, ErrJobExpired)
+		return true
+	}
+
+	// step3、检查最大尝试次数：最大尝试次数为0表示无限重试
+	if job.Payload().MaxTries == UnlimitedTries || job.Attempts() <= job.Payload().MaxTries {
+		return false
+	}
+
+	// step4、其他情况：执行job前检查就不通过，移除任务&&标记任务失败（最大尝试次数超过限制、持续执行超时、脏数据、意外中断的任务 等）
+	m.failJob(job, ErrMaxAttemptsExceeded)
+
+	return true
+}
+
+// isJobExpired 检查job自首次投递起是否已超过任务类设置的最长有效时长
+func (m *manager) isJobExpired(job JobIFace) bool {
+	enqueuedAt := job.Payload().EnqueuedAt
+	if enqueuedAt <= 0 {
+		return false
+	}
+	task, ok := m.taskByName(job.GetName())
+	if !ok {
+		return false
+	}
+	maxAgeTask, ok := task.(MaxAgeTask)
+	if !ok || maxAgeTask.MaxAge() <= 0 {
+		return false
+	}
+	return time.Since(time.Unix(enqueuedAt, 0)) > maxAgeTask.MaxAge()
+}
+
+// markJobAsFailedIfWillExceedMaxAttempts job执行`之后`检测尝试次数是否超限
+// 1、检查job执行是否超过基准时间以记录日志
+// 2、检查job执行尝试次数
+func (m *manager) markJobAsFailedIfWillExceedMaxAttempts(job JobIFace, err error) {
+	if job.IsDeleted() {
+		return
+	}
+
+	// step1、执行时长检查：超时记录超时日志
+	m.checkLongRunning(job)
+
+	// step2、检查最大尝试执行次数是否超限：最大尝试次数为0表示无限重试，始终放回重试
+	if job.Payload().MaxTries != UnlimitedTries && job.Attempts() >= job.Payload().MaxTries {
+		// 超过最大重试次数：本次执行失败 && 任务类最终执行失败 && delete任务
+		m.failJob(job, err)
+	} else {
+		// 任务可以重试：本次执行失败 && 任务类还可以重试 && release任务
+		delay := m.retryDelay(job)
+		_ = job.Release(int64(delay / time.Second))
+		m.metrics.JobRetried(jobQueueName(job), delay)
+		m.jobOutcome(job, JobReleased, JobOutcomeDetail{Delay: delay, Err: err})
+		m.emitJobEvent(EventJobRetried, job, m.workingWorkerID(job), err)
+		if task, ok := m.taskByName(job.GetName()); ok {
+			if retryAware, ok := task.(RetryAware); ok {
+				m.safeCallback(job, func() { retryAware.OnRetry(*job.Payload(), job.Attempts(), delay) })
+			}
+		}
+	}
+}
+
+// retryDelay 按重试间隔策略计算job放回重试的延迟时长，底层驱动延迟精度为秒，不足1秒的部分向上取整
+func (m *manager) retryDelay(job JobIFace) time.Duration {
+	strategy := m.backoffStrategy
+	if strategy == nil {
+		strategy = LinearBackoff{}
+	}
+
+	delay := strategy.NextDelay(job.Attempts(), time.Duration(job.Payload().RetryInterval)*time.Second)
+	if delay <= 0 {
+		return 0
+	}
+	if m.retryJitter > 0 {
+		// 在 [-jitter%, +jitter%] 范围内随机抖动，避免同时失败的大量job同时重试
+		spread := int64(delay) * int64(m.retryJitter) / 100
+		if spread > 0 {
+			delay += time.Duration(rand.Int63n(2*spread+1) - spread)
+		}
+	}
+	if remainder := delay % time.Second; remainder > 0 {
+		delay += time.Second - remainder
+	}
+	return delay
+}
+
+// failJob 失败的任务触发器
+func (m *manager) failJob(job JobIFace, err error) {
+	// -> 1、标记任务失败
+	job.MarkAsFailed()
+
+	// -> 2、任务状态未删除则删除任务
+	if job.IsDeleted() {
+		return
+	}
+	m.pushDeadLetter(job, err)
+	_ = job.Delete()
+	m.notifyJobFailed(job, err)
+	m.emitJobEvent(EventJobDeleted, job, m.workingWorkerID(job), nil)
+}
+
+// notifyJobFailed 记录job最终失败日志并触发各失败回调
+func (m *manager) notifyJobFailed(job JobIFace, err error) {
+	// tag log
+	m.jobLogger(job).Error(
+		textJobFailedLog,
+		field("queue", job.GetName()),
+		field("payload", job.Payload()),
+		field("error", err),
+	)
+
+	// -> 3、设置任务执行失败
+	job.Failed(err)
+	m.metrics.JobFailed(jobQueueName(job), err)
+	m.jobOutcome(job, JobFailed, JobOutcomeDetail{Err: err})
+	m.emitJobEvent(EventJobFailed, job, m.workingWorkerID(job), err)
+	if task, ok := m.taskByName(job.GetName()); ok {
+		if failureAware, ok := task.(FailureAware); ok {
+			m.safeCallback(job, func() { failureAware.OnFailure(*job.Payload(), err) })
+		}
+	}
+
+	// -> 4、queue级别依赖是否有设置失败任务处理器动作
+	m.recordFailedJob(job, err)
+}
+
+// jobOutcome 触发job处理结果回调：回调panic被捕获，避免在 runJob 的panic恢复流程中再次panic导致worker崩溃
+func (m *manager) jobOutcome(job JobIFace, outcome JobOutcome, detail JobOutcomeDetail) {
+	if m.onJobOutcome != nil {
+		m.safeCallback(job, func() { m.onJobOutcome(job, outcome, detail) })
+	}
+}
+
+// loggedJob 执行中的job：缓存job相关日志记录器，避免每条日志都重新解析payload、调用 LogFieldsTask
+type loggedJob struct {
+	JobIFace
+	logger Logger // job相关日志记录器
+}
+
+// jobLogger 获取job相关日志使用的日志记录器：任务类实现了 LogFieldsTask 时附加其返回的字段
+// 执行中的job使用 runJob 开始时缓存的日志记录器
+func (m *manager) jobLogger(job JobIFace) Logger {
+	if logged, ok := job.(*loggedJob); ok {
+		return logged.logger
+	}
+	task, ok := m.taskByName(job.GetName())
+	if !ok {
+		return m.workerLogger
+	}
+	return m.newJobLogger(task, job)
+}
+
+// newJobLogger 生成job相关日志记录器：解析payload并调用任务类 LogFieldsTask 获取附加字段
+func (m *manager) newJobLogger(task TaskIFace, job JobIFace) Logger {
+	fieldsTask, ok := task.(LogFieldsTask)
+	if !ok {
+		return m.workerLogger
+	}
+	body, err := m.rawBody(job)
+	if err != nil {
+		return m.workerLogger
+	}
+
+	var fields []Field
+	m.safeCallback(job, func() { fields = fieldsTask.LogFields(body) })
+	if len(fields) == 0 {
+		return m.workerLogger
+	}
+	return withFields(m.workerLogger, fields)
+}
+
+// safeCallback 执行任务类回调：捕获回调中的panic并记录日志，避免影响job后续处理
+func (m *manager) safeCallback(job JobIFace, callback func()) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			m.workerLogger.Error(
+				"queue.callback.panic",
+				field("stack", string(debug.Stack())),
+				field("queue", job.GetName()),
+				field("payload", job.Payload()),
+				field("panic", fmt.Sprintf("%v", recovered)),
+			)
+		}
+	}()
+	callback()
+}
+
+// pushDeadLetter 启用死信队列时将最终失败job的完整payload投递到死信队列，便于排查后手动重放
+// 1、投递前重置尝试次数、取出时刻，重放的job拥有完整的尝试次数
+// 2、消费死信队列时再次最终失败的job不再投递到死信队列，避免 name:dead:dead 逐级嵌套
+// 3、优先级子队列中的job投递到原队列的死信队列，即 name:dead
+func (m *manager) pushDeadLetter(job JobIFace, err error) {
+	if m.deadLetterSuffix == "" || strings.HasSuffix(jobQueueName(job), m.deadLetterSuffix) {
+		return
+	}
+
+	deadQueue := jobQueueName(job) + m.deadLetterSuffix
+	dead := *job.Payload()
+	dead.Attempts = 0
+	dead.PopTime = 0
+	dead.TimeoutAt = 0
+	payload, marshalErr := m.marshalJob(dead)
+	if marshalErr == nil {
+		marshalErr = job.Queue().Push(deadQueue, payload)
+	}
+	if marshalErr != nil {
+		m.jobLogger(job).Error(
+			"queue.dead.letter.push.failed",
+			field("queue", job.GetName()),
+			field("dead_queue", deadQueue),
+			field("payload", job.Payload()),
+			field("error", marshalErr),
+		)
+		return
+	}
+
+	m.jobLogger(job).Warn(
+		"queue.dead.letter.pushed",
+		field("queue", job.GetName()),
+		field("dead_queue", deadQueue),
+		field("payload", job.Payload()),
+		field("reason", err),
+	)
+}
+
+// recordFailedJob 触发记录可能的失败任务
+func (m *manager) recordFailedJob(job JobIFace, err error) {
+	for index, handler := range m.failedJobHandlers {
+		// 各处理器相互独立：某个处理器返回error或panic不影响后续处理器执行
+		var handlerErr error
+		m.safeCallback(job, func() { handlerErr = handler(job.Payload(), err) })
+		if handlerErr != nil {
+			m.jobLogger(job).Error(
+				"queue.failed.handler.error",
+				field("queue", job.GetName()),
+				field("payload", job.Payload()),
+				field("handler", index),
+				field("error", handlerErr),
+			)
+		}
+	}
+	if m.failedRecordHandler != nil {
+		record := m.failureRecord(job, err)
+		var handlerErr error
+		m.safeCallback(job, func() { handlerErr = m.failedRecordHandler(jobQueueName(job), record, err) })
+		if handlerErr != nil {
+			m.jobLogger(job).Error(
+				"queue.failed.record.handler.error",
+				field("queue", job.GetName()),
+				field("payload", job.Payload()),
+				field("error", handlerErr),
+			)
+		}
+	}
+}
+
+// failureRecord 获取失败job的失败记录：任务类实现了 FailureRecorder 则使用其转换结果，否则为job的payload
+// 转换时panic则降级为job的payload
+func (m *manager) failureRecord(job JobIFace, err error) interface{} {
+	var record interface{} = job.Payload()
+	task, _ := m.taskByName(job.GetName())
+	if recorder, ok := task.(FailureRecorder); ok {
+		m.safeCallback(job, func() { record = recorder.FailureRecord(*job.Payload(), err) })
+	}
+	return record
+}
+
+// Dispatch 按任务name投递一个立即执行的队列Job任务
+// job的ID、最大尝试次数、重试间隔、超时时长取自已注册的任务类，任务参数按队列的编码设置序列化
+func (m *manager) Dispatch(name string, payload interface{}) error {
+	return m.DispatchAfter(name, 0, payload)
+}
+
+// DispatchAfter 按任务name投递一个延迟指定时长执行的队列Job任务，delay不大于0时立即执行
+// 优雅关闭中（或已关闭）返回 ErrQueueClosed
+func (m *manager) DispatchAfter(name string, delay time.Duration, payload interface{}) error {
+	if m.shuttingDown() {
+		return ErrQueueClosed
+	}
+	task, exist := m.registeredTask(name)
+	if !exist {
+		return fmt.Errorf("queue %s do not bootstrap", name)
+	}
+
+	availableAt := time.Now()
+	if delay > 0 {
+		availableAt = availableAt.Add(delay)
+	}
+	queuePayload, err := m.basic.marshalPayload(task, payload, nil, availableAt, 0)
+	if nil != err {
+		return fmt.Errorf("queue %s job param marshal failed: %s", name, err.Error())
+	}
+
+	if delay > 0 {
+		return m.queue.Later(name, delay, queuePayload)
+	}
+	return m.queue.Push(name, queuePayload)
+}
+
+// requeueAll 将指定队列保留中的job全部放回队列，跳过当前实例执行中的job
+func (m *manager) requeueAll(name string) (count int, err error) {
+	requeuer, ok := m.queue.(ReservedRequeuer)
+	if !ok {
+		return 0, fmt.Errorf("queue driver do not support requeue reserved jobs")
+	}
+
+	running := func(id string) bool {
+		m.lock.Lock()
+		defer m.lock.Unlock()
+		_, running := m.inWorkingMap[id]
+		return running
+	}
+	for _, queue := range m.priorityQueueNames(name) {
+		n, requeueErr := requeuer.RequeueReserved(queue, running)
+		count += n
+		if requeueErr != nil {
+			err = requeueErr
+			break
+		}
+	}
+
+	m.workerLogger.Warn(
+		"queue.requeue.all",
+		field("queue", name),
+		field("count", count),
+		field("error", err),
+	)
+
+	return count, err
+}
+
+// priorityQueueNames 队列及其各优先级子队列名称，按执行顺序自高优先级到低优先级排列
+// 1、队列名称为已注册的 PriorityTask 任务类名称时包含各优先级子队列，否则仅为该队列本身
+func (m *manager) priorityQueueNames(name string) []string {
+	levels := 1
+	if task, ok := m.registeredTask(name); ok {
+		levels = priorityLevels(task)
+	}
+
+	names := make([]string, 0, levels)
+	for priority := levels - 1; priority >= 0; priority-- {
+		names = append(names, priorityQueueName(name, priority))
+	}
+	return names
+}
+
+// taskByName 按队列名称获取任务类：优先匹配任务类名称，其次匹配任务类旧名称（别名）
+func (m *manager) taskByName(name string) (task TaskIFace, ok bool) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if task, ok = m.tasks[name]; ok {
+		return task, true
+	}
+	if task, ok = m.aliases[name]; ok {
+		return task, true
+	}
+	// 优先级子队列中的job由原队列的任务类执行
+	if base, isPriority := priorityBaseName(name); isPriority {
+		task, ok = m.tasks[base]
+	}
+	return task, ok
 }
 
-// looperJitter looper循环器间隔抖动
-func (m *manager) looperJitter() time.Duration {
-	m.jitter = m.jitter + time.Duration(rand.Intn(int(jitterBase/3)))
-	if m.jitter > 1*time.Second {
-		m.jitter = jitterBase
+// stats 运行状态快照：持有锁读取worker状态与执行中job，保证快照一致
+func (m *manager) stats() Stats {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	stats := Stats{
+		InWorkingCount:  int64(len(m.inWorkingMap)),
+		RegisteredTasks: int64(len(m.tasks)),
+		ShuttingDown:    m.shuttingDown(),
+		Paused:          m.paused.isSet(),
+	}
+	stats.ExecuteInFlight, stats.ExecuteLimit = m.executeInFlight()
+	stats.LooperBusy = time.Duration(atomic.LoadInt64(&m.looperBusyNanos))
+	stats.LooperIdle = time.Duration(atomic.LoadInt64(&m.looperIdleNanos))
+	if total := stats.LooperBusy + stats.LooperIdle; total > 0 {
+		stats.LooperIdleRatio = float64(stats.LooperIdle) / float64(total)
+	}
+
+	for _, node := range m.workerStatus {
+		if node.isSet() {
+			stats.ActiveWorkers++
+		}
+	}
+	if m.inStarted.isSet() && m.concurrent > stats.ActiveWorkers {
+		stats.IdleWorkers = m.concurrent - stats.ActiveWorkers
 	}
 
-	return m.jitter
+	return stats
 }
 
-// markJobAsFailedIfAlreadyExceedsMaxAttempts job执行`之前`检测尝试次数是否超限
-// 1、如果超限则方法体内部清理任务并返回true，表示该job需要停止执行
-// 2、如果未超限则返回false
-func (m *manager) markJobAsFailedIfAlreadyExceedsMaxAttempts(job JobIFace) (needSop bool) {
-	// step1、执行时长检查，持续执行超过设置的超时时长则记录日志
-	if time.Now().Sub(job.PopTime()) >= job.Timeout() {
-		m.logger.Warn(
-			textJobTooLong,
-			zap.String("queue", job.GetName()),
-			zap.Any("payload", job.Payload()),
-			zap.Time("pop_time", job.PopTime()),
-		)
-	}
+// config 生效中的配置快照：返回值拷贝，不暴露内部引用
+func (m *manager) config() Config {
+	m.lock.Lock()
+	defer m.lock.Unlock()
 
-	// step2、检查最大尝试次数
-	if job.Attempts() <= job.Payload().MaxTries {
-		return false
+	config := Config{
+		Concurrent:              m.concurrent,
+		Loopers:                 m.loopers,
+		PopBatchSize:            int(m.popBatchSize),
+		PromoteInterval:         m.promoteInterval,
+		DefaultTimeout:          m.defaultTimeout,
+		MaxTimeout:              m.maxTimeout,
+		LooperJitterMin:         m.looperIntervalMin,
+		LooperJitterMax:         m.looperIntervalMax,
+		ChannelBuffer:           cap(m.channel),
+		FastQueues:              make([]string, 0, len(m.fastQueues)),
+		FastWorkers:             m.fastWorkers,
+		DedicatedWorkers:        make(map[string]int64, len(m.dedicatedPools)),
+		ExecuteLimit:            int64(cap(m.executeSemaphore)),
+		RetryJitter:             m.retryJitter,
+		Tasks:                   make([]string, 0, len(m.tasks)),
+		Aliases:                 make(map[string]string, len(m.aliases)),
+		PartitionRedeliverDelay: partitionRedeliverDelay,
+		FailedJobHandler:        len(m.failedJobHandlers) > 0,
+		FailedRecordHandler:     m.failedRecordHandler != nil,
+		DuplicatePolicy:         m.duplicatePolicy != nil,
+		PanicNormalizer:         m.panicNormalizer != nil,
+		PanicHandler:            m.panicHandler != nil,
+		ProgressSink:            m.progressSink != nil,
+		ResultStore:             m.resultStore != nil,
+		DeadLetterSuffix:        m.deadLetterSuffix,
+		ForceRequeueOnShutdown:  m.forceRequeue,
+		ShutdownQuietPeriod:     m.quietPeriod,
+		UnregisteredDelay:       m.unregisteredDelay,
+		UnregisteredMaxRetries:  m.unregisteredMax,
+		DriftThreshold:          m.driftThreshold,
+		ReserveTimeout:          m.reserveTimeout,
+		CompletedDedupWindow:    m.completedDedupWindow(),
+		StuckWindow:             m.stuckWindow,
+		Started:                 m.inStarted.isSet(),
+		ShuttingDown:            m.shuttingDown(),
 	}
+	for name := range m.fastQueues {
+		config.FastQueues = append(config.FastQueues, name)
+	}
+	for name, pool := range m.dedicatedPools {
+		config.DedicatedWorkers[name] = pool.workers
+	}
+	for name := range m.tasks {
+		config.Tasks = append(config.Tasks, name)
+	}
+	for alias, task := range m.aliases {
+		config.Aliases[alias] = task.Name()
+	}
+	sort.Strings(config.FastQueues)
+	sort.Strings(config.Tasks)
 
-	// step3、其他情况：执行job前检查就不通过，移除任务&&标记任务失败（最大尝试次数超过限制、持续执行超时、脏数据、意外中断的任务 等）
-	m.failJob(job, ErrMaxAttemptsExceeded)
-
-	return true
+	return config
 }
 
-// markJobAsFailedIfWillExceedMaxAttempts job执行`之后`检测尝试次数是否超限
-// 1、检查job执行是否超过基准时间以记录日志
-// 2、检查job执行尝试次数
-func (m *manager) markJobAsFailedIfWillExceedMaxAttempts(job JobIFace, err error) {
-	if job.IsDeleted() {
-		return
-	}
+// taskNames 已注册的任务类名称，按名称排序
+func (m *manager) taskNames() []string {
+	m.lock.Lock()
+	defer m.lock.Unlock()
 
-	// step1、执行时长检查：超时记录超时日志
-	if time.Now().Sub(job.PopTime()) >= job.Timeout() {
-		m.logger.Warn(
-			textJobTooLong,
-			zap.String("queue", job.GetName()),
-			zap.Any("payload", job.Payload()),
-			zap.Time("pop_time", job.PopTime()),
-		)
+	names := make([]string, 0, len(m.tasks))
+	for name := range m.tasks {
+		names = append(names, name)
 	}
+	sort.Strings(names)
+	return names
+}
 
-	// step2、检查最大尝试执行次数是否超限
-	if job.Attempts() >= job.Payload().MaxTries {
-		// 超过最大重试次数：本次执行失败 && 任务类最终执行失败 && delete任务
-		m.failJob(job, err)
-	} else {
-		// 任务可以重试：本次执行失败 && 任务类还可以重试 && release任务
-		_ = job.Release(job.Payload().RetryInterval)
+// registeredTasks 已注册的任务类配置信息，按名称排序
+func (m *manager) registeredTasks() []TaskInfo {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	infos := make([]TaskInfo, 0, len(m.tasks))
+	for name, task := range m.tasks {
+		info := TaskInfo{
+			Name:          name,
+			MaxTries:      task.MaxTries(),
+			RetryInterval: task.RetryInterval(),
+			Timeout:       task.Timeout(),
+			Aliases:       []string{},
+		}
+		for alias, aliasTask := range m.aliases {
+			if aliasTask.Name() == name {
+				info.Aliases = append(info.Aliases, alias)
+			}
+		}
+		sort.Strings(info.Aliases)
+		infos = append(infos, info)
 	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
 }
 
-// failJob 失败的任务触发器
-func (m *manager) failJob(job JobIFace, err error) {
-	// -> 1、标记任务失败
-	job.MarkAsFailed()
+// runningJobs 当前实例执行中的job快照
+func (m *manager) runningJobs() []RunningJob {
+	m.lock.Lock()
+	defer m.lock.Unlock()
 
-	// -> 2、任务状态未删除则删除任务
-	if job.IsDeleted() {
-		return
+	jobs := make([]RunningJob, 0, len(m.inWorkingMap))
+	for id, working := range m.inWorkingMap {
+		jobs = append(jobs, RunningJob{
+			ID:       id,
+			Name:     jobQueueName(working.job),
+			WorkerID: working.workerID,
+			Attempts: working.job.Attempts(),
+			PopTime:  working.job.PopTime(),
+		})
 	}
-	_ = job.Delete()
-
-	// tag log
-	m.logger.Error(
-		textJobFailedLog,
-		zap.String("queue", job.GetName()),
-		zap.Any("payload", job.Payload()),
-		zap.Error(err),
-	)
+	return jobs
+}
 
-	// -> 3、设置任务执行失败
-	job.Failed(err)
+// waitInFlight 等待调用时刻执行中的job全部结束，期间不停止looper取出新job，之后开始执行的job不等待
+func (m *manager) waitInFlight(ctx context.Context) error {
+	m.lock.Lock()
+	snapshot := make(map[string]*workingJob, len(m.inWorkingMap))
+	for id, working := range m.inWorkingMap {
+		snapshot[id] = working
+	}
+	m.lock.Unlock()
 
-	// -> 4、queue级别依赖是否有设置失败任务处理器动作
-	m.recordFailedJob(job, err)
-}
+	ticker := time.NewTicker(waitInFlightPollInterval)
+	defer ticker.Stop()
+	for {
+		// 同ID的job再次开始执行时记录已被替换，按记录实例判断快照中的job是否仍在执行
+		m.lock.Lock()
+		for id, working := range snapshot {
+			if m.inWorkingMap[id] != working {
+				delete(snapshot, id)
+			}
+		}
+		m.lock.Unlock()
 
-// recordFailedJob 触发记录可能的失败任务
-func (m *manager) recordFailedJob(job JobIFace, err error) {
-	if m.failedJobHandler != nil {
-		_ = m.failedJobHandler(job.Payload(), err)
+		if len(snapshot) == 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
 	}
 }
 
-// shutDown 优雅停止队列
-// 1、停止轮询loop进程，不再投递job
-// 2、上下文设置的等待超时时间内尽量允许执行中的job顺利完成，超时终止的 :reserved 有序队列将在下次执行时再次投递尝试执行
+// shutDown 优雅停止队列，按以下顺序依次停止，前一步的协程全部退出后再进行下一步，保证停止过程中不再产生新的job
+// 1、停止周期任务调度器、延迟任务晋升协程，不再产生新的job
+// 2、停止轮询loop进程，不再投递job；所有looper退出后关闭job chan，worker执行完缓冲中的job后退出
+// 3、上下文设置的等待超时时间内尽量允许执行中的job顺利完成，超时终止的 :reserved 有序队列将在下次执行时再次投递尝试执行
+// 4、执行中的job均超过各自任务类的优雅关闭等待时长（见 ShutdownGraceTask）后不再等待，返回 ErrShutdownGraceExceeded
+// 5、设置了强制关闭放回时，等待超时后将任务类执行中的job立即放回队列，重启后即可再次取出执行
+// 返回nil时队列启动的所有协程均已退出
 // @param ctx 超时上下文
 func (m *manager) shutDown(ctx context.Context) (err error) {
 	m.inShutdown.setTrue()
 
-	// 关闭用于控制looper协程的`关闭chan`：这样looper就停止循环
-	m.closeDoneChanLocked()
+	// step1、停止周期任务调度器、延迟任务晋升协程
+	// 等待超时也需关闭looper与worker的`关闭chan`：否则返回后looper仍在取出job、worker仍在执行
+	m.closeProducerDone()
+	if err = waitGroupWithContext(ctx, &m.producerWg); err != nil {
+		m.closeDoneChan()
+		m.requeueInWorking(err)
+		return err
+	}
+
+	// step2、关闭用于控制looper协程的`关闭chan`：这样looper就停止循环
+	m.closeDoneChan()
+	if err = waitGroupWithContext(ctx, &m.looperWg); err != nil {
+		m.requeueInWorking(err)
+		return err
+	}
 
 	// 优雅关闭等待时长逐步递增实现
 	pollIntervalBase := time.Millisecond
@@ -398,16 +2661,27 @@ func (m *manager) shutDown(ctx context.Context) (err error) {
 		return interval
 	}
 
-	m.logger.Info("try graceful shutdown queue, please wait seconds")
+	m.shutdownLogger.Info("try graceful shutdown queue, please wait seconds")
 
 	timer := time.NewTimer(nextPollInterval())
 	defer timer.Stop()
+	shutdownAt := time.Now()
 	for {
-		if m.isWorkersDown() {
-			return nil
+		// 执行中job数持有锁读取，报告为0且所有worker均已空闲才返回nil
+		remaining := m.inWorkingCount()
+		if m.onShutdownProgress != nil {
+			m.onShutdownProgress(remaining)
+		}
+		if remaining == 0 && m.isWorkersDown() && atomic.LoadInt64(&m.liveWorkers) == 0 {
+			return m.waitQuietPeriod(ctx)
+		}
+		if m.isInWorkingGraceExceeded(shutdownAt) {
+			m.requeueInWorking(ErrShutdownGraceExceeded)
+			return ErrShutdownGraceExceeded
 		}
 		select {
 		case <-ctx.Done():
+			m.requeueInWorking(ctx.Err())
 			return ctx.Err()
 		case <-timer.C:
 			timer.Reset(nextPollInterval())
@@ -415,6 +2689,211 @@ func (m *manager) shutDown(ctx context.Context) (err error) {
 	}
 }
 
+// waitQuietPeriod 所有worker空闲后再静默等待设置的时长，留给任务类脱离执行流程启动的协程收尾
+// 此时已没有执行中的job，ctx先结束时仅返回ctx的error，无需放回job
+func (m *manager) waitQuietPeriod(ctx context.Context) error {
+	if m.quietPeriod <= 0 {
+		return nil
+	}
+
+	m.shutdownLogger.Info("all workers idle, wait quiet period before shutdown", field("quiet_period", m.quietPeriod))
+
+	timer := time.NewTimer(m.quietPeriod)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// requeueInWorking 优雅关闭等待超时后将任务类执行中的job放回队列，未设置强制关闭放回时不做处理
+// 1、与 Release 不同，放回不消耗job的尝试次数，避免仅执行1次的job重启后直接判定失败
+// 2、执行协程此后结束时不再处理执行结果；尚未开始执行的job仍由执行协程处理
+// @param reason 强制关闭原因
+func (m *manager) requeueInWorking(reason error) {
+	if !m.forceRequeue {
+		return
+	}
+
+	m.lock.Lock()
+	workings := make([]*workingJob, 0, len(m.inWorkingMap))
+	for _, working := range m.inWorkingMap {
+		workings = append(workings, working)
+	}
+	m.lock.Unlock()
+
+	for _, working := range workings {
+		// 至多一次投递语义的job已在执行前删除，不可放回队列
+		if m.deliveryMode(working.task) == AtMostOnce {
+			continue
+		}
+		if !working.transit(workingExecuting, workingSettled) {
+			continue
+		}
+		if err := m.redeliver(working.job, 0); err != nil {
+			m.shutdownLogger.Error(
+				"queue.shutdown.requeue.failed",
+				field("queue", working.job.GetName()),
+				field("payload", working.job.Payload()),
+				field("error", err),
+			)
+			continue
+		}
+		m.shutdownLogger.Warn(
+			textJobRequeued,
+			field("queue", working.job.GetName()),
+			field("worker_id", working.workerID),
+			field("payload", working.job.Payload()),
+			field("reason", reason),
+		)
+		m.jobOutcome(working.job, JobRedelivered, JobOutcomeDetail{Err: reason})
+	}
+}
+
+// inWorkingCount 当前实例执行中的job数量
+func (m *manager) inWorkingCount() int {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return len(m.inWorkingMap)
+}
+
+// isInWorkingGraceExceeded 检查执行中的job是否均已超过各自任务类的优雅关闭等待时长
+// 任务类实现了 ShutdownGraceTask 则使用其返回的等待时长，否则使用任务类的超时时长
+func (m *manager) isInWorkingGraceExceeded(shutdownAt time.Time) bool {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if len(m.inWorkingMap) == 0 {
+		return false
+	}
+
+	for _, working := range m.inWorkingMap {
+		grace := working.task.Timeout()
+		if graceTask, ok := working.task.(ShutdownGraceTask); ok {
+			grace = graceTask.ShutdownGrace()
+		}
+		if time.Now().Sub(shutdownAt) < grace {
+			return false
+		}
+	}
+
+	for _, working := range m.inWorkingMap {
+		m.shutdownLogger.Warn(
+			ErrShutdownGraceExceeded.Error(),
+			field("queue", working.job.GetName()),
+			field("worker_id", working.workerID),
+			field("payload", working.job.Payload()),
+		)
+	}
+	return true
+}
+
+// processUntil 启动队列并运行指定时长后优雅停止
+// 1、运行时长到达或上下文提前结束时开始优雅停止
+// 2、优雅停止的等待上限为上下文截止时刻的剩余时长，上下文未设置截止时刻则等待执行中的job结束
+func (m *manager) processUntil(ctx context.Context, duration time.Duration) (err error) {
+	if err = m.start(); err != nil {
+		return err
+	}
+
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		m.shutdownLogger.Info("process duration reached, queue begin shutdown")
+	case <-ctx.Done():
+		m.shutdownLogger.Info("process context done, queue begin shutdown early")
+	}
+
+	shutdownCtx, cancel := detachedShutdownContext(ctx)
+	defer cancel()
+
+	return m.shutDown(shutdownCtx)
+}
+
+// detachedShutdownContext 由运行期间的ctx派生优雅停止使用的ctx：不随ctx取消而取消
+// 1、ctx设置了尚未到达的截止时刻时沿用该截止时刻
+// 2、ctx已结束或未设置截止时刻时不设截止时刻，等待执行中的job按各自超时时长结束
+func detachedShutdownContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if deadline, ok := ctx.Deadline(); ok && time.Now().Before(deadline) {
+		return context.WithDeadline(context.Background(), deadline)
+	}
+	return context.WithCancel(context.Background())
+}
+
+// drain 持续消费直至所有队列为空且没有执行中的job后优雅停止，队列未启动时先启动
+// ctx结束时不再等待队列排空，停止取出新job并等待执行中的job结束后返回ctx的错误
+func (m *manager) drain(ctx context.Context) (err error) {
+	if !m.inStarted.isSet() {
+		if err = m.start(); err != nil {
+			return err
+		}
+	}
+
+	ticker := time.NewTicker(waitInFlightPollInterval)
+	defer ticker.Stop()
+	for !m.isDrained() {
+		select {
+		case <-ctx.Done():
+			m.shutdownLogger.Warn("drain context done before queues empty, queue begin shutdown", field("error", ctx.Err()))
+			shutdownCtx, cancel := detachedShutdownContext(ctx)
+			defer cancel()
+			if err = m.shutDown(shutdownCtx); err != nil {
+				return err
+			}
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+
+	m.shutdownLogger.Info("all queues drained, queue begin shutdown")
+	shutdownCtx, cancel := detachedShutdownContext(ctx)
+	defer cancel()
+	return m.shutDown(shutdownCtx)
+}
+
+// isDrained 检查是否已排空：所有消费的队列（含延迟中、保留中的job）为空，且没有取出中、待执行、执行中的job
+func (m *manager) isDrained() bool {
+	if atomic.LoadInt64(&m.loopersInPass) != 0 || atomic.LoadInt64(&m.undoneBatches) != 0 || m.inWorkingCount() != 0 {
+		return false
+	}
+	for _, name := range m.consumeQueueNames() {
+		if m.queue.Size(name) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// runUntilSignal 启动队列并阻塞直至收到SIGINT、SIGTERM信号后优雅停止
+// @param timeout 优雅停止的等待时长
+// 启动前即监听信号，避免启动期间收到的信号未经优雅停止直接终止进程；由其他方式触发关闭时不再等待信号直接返回
+func (m *manager) runUntilSignal(timeout time.Duration) (err error) {
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(quit)
+
+	if err = m.start(); err != nil {
+		return err
+	}
+
+	select {
+	case sig := <-quit:
+		m.shutdownLogger.Info("receive exit signal, queue begin shutdown", field("signal", sig.String()))
+	case <-m.getDoneChan():
+		m.shutdownLogger.Info("queue shutdown by other caller, stop waiting exit signal")
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	return m.shutDown(ctx)
+}
+
 // getDoneChan 带初始化的获取关闭控制chan
 func (m *manager) getDoneChan() <-chan struct{} {
 	m.lock.Lock()
@@ -430,8 +2909,40 @@ func (m *manager) getDoneChanLocked() chan struct{} {
 	return m.doneChan
 }
 
-// closeDoneChanLocked 关闭用于关闭控制的chan（继而发信号告诉looper和worker优雅停止）
-func (m *manager) closeDoneChanLocked() {
+// closeProducerDone 通知周期任务调度器、延迟任务晋升协程退出，多次调用仅关闭一次
+func (m *manager) closeProducerDone() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	select {
+	case <-m.producerDone:
+	default:
+		close(m.producerDone)
+	}
+}
+
+// waitGroupWithContext 等待WaitGroup归零，ctx取消时不再等待返回ctx错误
+// ctx取消时等待协程不会立即退出，调用方需已发出退出信号，WaitGroup归零后等待协程随之退出
+func waitGroupWithContext(ctx context.Context, wg *sync.WaitGroup) error {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// closeDoneChan 关闭用于关闭控制的chan（继而发信号告诉looper和worker优雅停止），多次调用仅关闭一次
+func (m *manager) closeDoneChan() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
 	ch := m.getDoneChanLocked()
 	select {
 	case <-ch:
@@ -451,15 +2962,26 @@ func (m *manager) setWorkerStatus(workerID int64, isRun bool) {
 		m.workerStatus[workerID] = node
 	}
 
+	// 状态变更时同步空闲worker计数
 	if isRun {
+		if !node.isSet() {
+			atomic.AddInt64(m.idleCounterLocked(workerID), -1)
+		}
 		node.setTrue()
 	} else {
+		if node.isSet() {
+			atomic.AddInt64(m.idleCounterLocked(workerID), 1)
+		}
 		node.setFalse()
 	}
+	atomic.StoreInt64(&m.lastActivity, time.Now().UnixNano())
 }
 
 // isWorkersDown 检查是否所有worker当前工作任务均处于down状态
 func (m *manager) isWorkersDown() (down bool) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
 	for _, node := range m.workerStatus {
 		if node.isSet() {
 			return false
@@ -468,6 +2990,100 @@ func (m *manager) isWorkersDown() (down bool) {
 	return true
 }
 
+// healthy 检查队列是否健康：关闭中、或所有worker均在执行且状态无变化超过卡死判定时长时不健康
+func (m *manager) healthy() bool {
+	if !m.inStarted.isSet() || m.shuttingDown() {
+		return false
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.stuckWindow <= 0 {
+		return true
+	}
+
+	for _, node := range m.workerStatus {
+		if !node.isSet() {
+			return true
+		}
+	}
+	lastActivity := atomic.LoadInt64(&m.lastActivity)
+	return lastActivity == 0 || time.Since(time.Unix(0, lastActivity)) <= m.stuckWindow
+}
+
+// reload 以新的任务类集合替换已注册的任务类：暂停取出job，等待已取出的job全部执行结束后替换，再恢复取出
+// 1、未启动时直接替换；替换失败（例如任务类名称冲突）时保留原任务类集合
+// 2、ctx取消时放弃替换并恢复取出，已注册的任务类不变
+func (m *manager) reload(ctx context.Context, tasks []TaskIFace) error {
+	if m.shuttingDown() {
+		return ErrQueueClosed
+	}
+
+	if m.inStarted.isSet() {
+		// 调用前已暂停时替换后保持暂停
+		wasPaused := m.paused.isSet()
+		m.paused.setTrue()
+		defer func() {
+			if !wasPaused {
+				m.paused.setFalse()
+			}
+		}()
+
+		if err := m.waitDrained(ctx); err != nil {
+			return err
+		}
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	prevTasks, prevAliases, prevLimiters, prevBreakers := m.tasks, m.aliases, m.limiters, m.breakers
+	m.tasks = make(map[string]TaskIFace, len(tasks))
+	m.aliases = make(map[string]TaskIFace)
+	m.limiters = make(map[string]*rate.Limiter)
+	m.breakers = make(map[string]*circuitBreaker)
+	for _, task := range tasks {
+		if err := m.registerLocked(task); err != nil {
+			m.tasks, m.aliases, m.limiters, m.breakers = prevTasks, prevAliases, prevLimiters, prevBreakers
+			return err
+		}
+	}
+	m.executors = make(map[string]cachedExecutor, len(m.tasks))
+	m.notifyScheduleChanged()
+
+	m.logger.Info("queue.tasks.reloaded", field("tasks", len(m.tasks)), field("aliases", len(m.aliases)))
+	return nil
+}
+
+// waitDrained 暂停取出job后等待looper结束本轮遍历，以及已投递给worker的job全部执行结束
+func (m *manager) waitDrained(ctx context.Context) error {
+	ticker := time.NewTicker(waitInFlightPollInterval)
+	defer ticker.Stop()
+	for {
+		if atomic.LoadInt64(&m.loopersInPass) == 0 && atomic.LoadInt64(&m.undoneBatches) == 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// pause 暂停取出job
+func (m *manager) pause() {
+	m.paused.setTrue()
+	m.looperLogger.Info("queue paused")
+}
+
+// resume 恢复取出job
+func (m *manager) resume() {
+	m.paused.setFalse()
+	m.looperLogger.Info("queue resumed")
+}
+
 // shuttingDown 检测当前队列是否处于正在关闭中的状态
 func (m *manager) shuttingDown() bool {
 	return m.inShutdown.isSet()
@@ -0,0 +1,48 @@
+/*
+ * @Time   : 2026/10/15 下午19:00
+ * @Email  : jjonline@jjonline.cn
+ */
+package queue
+
+import (
+	"time"
+)
+
+// MetricsCollector 队列指标采集器：在job生命周期各节点被调用，用于接入Prometheus等监控系统
+// 方法在looper或worker协程中同步调用，实现需并发安全且不应执行耗时操作
+type MetricsCollector interface {
+	// JobPopped 从队列取出了job
+	// @param queue 队列名称
+	// @param count 本次取出的job数量
+	JobPopped(queue string, count int)
+	// JobExecuted 任务类执行结束（含执行成功、返回error、panic、超时）
+	// @param queue    队列名称
+	// @param duration 本次取出至执行结束的时长
+	// @param err      执行结果，执行成功为nil
+	JobExecuted(queue string, duration time.Duration, err error)
+	// JobRetried 执行失败的job放回队列稍后重试
+	// @param queue 队列名称
+	// @param delay 重试延迟时长
+	JobRetried(queue string, delay time.Duration)
+	// JobFailed job尝试次数耗尽最终失败
+	// @param queue 队列名称
+	// @param err   最终失败原因
+	JobFailed(queue string, err error)
+}
+
+// nopMetrics 不采集任何指标的默认采集器
+type nopMetrics struct{}
+
+func (nopMetrics) JobPopped(queue string, count int)                           {}
+func (nopMetrics) JobExecuted(queue string, duration time.Duration, err error) {}
+func (nopMetrics) JobRetried(queue string, delay time.Duration)                {}
+func (nopMetrics) JobFailed(queue string, err error)                           {}
+
+// DeliveryLatencyCollector 可选实现的指标采集器契约：采集job投递延迟
+// 投递延迟为job首次被取出时刻与计划可被执行时刻的差值，用于诊断队列负载较高时job延后执行的问题
+type DeliveryLatencyCollector interface {
+	// JobDeliveryLatency job首次被取出时的投递延迟
+	// @param queue   队列名称
+	// @param latency 投递延迟
+	JobDeliveryLatency(queue string, latency time.Duration)
+}
@@ -0,0 +1,35 @@
+/*
+ * @Time   : 2026/10/16 下午15:00
+ * @Email  : jjonline@jjonline.cn
+ */
+package queue
+
+import (
+	"encoding/json"
+)
+
+// JSONPayloadCodec 默认的json格式job序列化器
+type JSONPayloadCodec struct{}
+
+// Name implement PayloadCodec
+func (c JSONPayloadCodec) Name() string {
+	return PayloadFormatJSON
+}
+
+// Marshal implement PayloadCodec
+func (c JSONPayloadCodec) Marshal(payload Payload) ([]byte, error) {
+	return json.Marshal(payload)
+}
+
+// Unmarshal implement PayloadCodec
+func (c JSONPayloadCodec) Unmarshal(data []byte, payload *Payload) error {
+	return json.Unmarshal(data, payload)
+}
+
+// payloadCodecOf 获取job序列化器，未设置时使用json
+func payloadCodecOf(codec PayloadCodec) PayloadCodec {
+	if codec == nil {
+		return JSONPayloadCodec{}
+	}
+	return codec
+}
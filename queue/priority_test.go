@@ -0,0 +1,80 @@
+/*
+ * @Time   : 2026/10/18 下午14:00
+ * @Email  : jjonline@jjonline.cn
+ */
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// priorityCountTask 测试用优先级任务类
+type priorityCountTask struct {
+	countTask
+}
+
+func (task *priorityCountTask) PriorityLevels() int {
+	return 3
+}
+
+// TestPriorityQueueSizeAndPeek 按状态统计、查看队列时包含各优先级子队列，且高优先级job在前
+func TestPriorityQueueSizeAndPeek(t *testing.T) {
+	task := &priorityCountTask{countTask{name: "priority_size"}}
+	q := newTestQueue(t, 1, task)
+	if err := q.Dispatch(task, "low"); err != nil {
+		t.Fatalf("dispatch: %v", err)
+	}
+	if err := q.Dispatch(task, "high", WithPriority(2)); err != nil {
+		t.Fatalf("dispatch: %v", err)
+	}
+
+	size, err := q.QueueSize(task.Name())
+	if err != nil || size.Pending != 2 {
+		t.Fatalf("expected 2 pending jobs, got %+v %v", size, err)
+	}
+	payloads, err := q.Peek(task.Name(), 10)
+	if err != nil || len(payloads) != 2 {
+		t.Fatalf("peek: %v %v", payloads, err)
+	}
+	first, err := q.Peek(task.Name(), 1)
+	if err != nil || len(first) != 1 || first[0].ID != payloads[0].ID {
+		t.Fatalf("peek first: %v %v", first, err)
+	}
+	if body := string(first[0].Payload); body != "high" {
+		t.Fatalf("expected high priority job first, got %q", body)
+	}
+}
+
+// TestPriorityJobUsesBaseQueueName 优先级子队列中的job按原队列名称统计执行时长、投递死信队列
+func TestPriorityJobUsesBaseQueueName(t *testing.T) {
+	fail := func(ctx context.Context, job *RawBody) error {
+		if job.String() == "fail" {
+			return errors.New("always fail")
+		}
+		return nil
+	}
+	task := &priorityCountTask{countTask{name: "priority_base", maxTries: 1, handler: fail}}
+	q := newTestQueue(t, 1, task)
+	q.SetDeadLetterQueue(":dead")
+	if err := q.Dispatch(task, "ok", WithPriority(2)); err != nil {
+		t.Fatalf("dispatch: %v", err)
+	}
+	if err := q.Dispatch(task, "fail", WithPriority(2)); err != nil {
+		t.Fatalf("dispatch: %v", err)
+	}
+	if err := q.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer q.ShutDown(context.Background())
+
+	waitFor(t, 5*time.Second, func() bool { return q.queue.Size(task.Name()+":dead") == 1 })
+	if size := q.queue.Size(priorityQueueName(task.Name(), 2) + ":dead"); size != 0 {
+		t.Fatalf("expected no priority sub-queue dead letter, got %d", size)
+	}
+	if stats := q.LatencyStats(task.Name()); stats.Samples != 1 {
+		t.Fatalf("expected 1 latency sample for base queue, got %d", stats.Samples)
+	}
+}
@@ -0,0 +1,51 @@
+/*
+ * @Time   : 2026/10/15 下午22:00
+ * @Email  : jjonline@jjonline.cn
+ */
+package queue
+
+import (
+	"context"
+)
+
+// progressContextKey 执行上下文中job执行进度报告器的key
+type progressContextKey struct{}
+
+// jobProgress job执行进度报告器：将任务类报告的进度转发给进度接收方法
+type jobProgress struct {
+	manager *manager
+	job     JobIFace
+}
+
+// Report implement Progress
+func (p *jobProgress) Report(percent float64, msg string) {
+	sink := p.manager.progressSink
+	if sink == nil {
+		return
+	}
+	if percent < 0 {
+		percent = 0
+	} else if percent > 100 {
+		percent = 100
+	}
+	p.manager.safeCallback(p.job, func() { sink(p.job, percent, msg) })
+}
+
+// nopProgress 丢弃进度的报告器，执行上下文中没有进度报告器时使用
+type nopProgress struct{}
+
+// Report implement Progress
+func (nopProgress) Report(float64, string) {}
+
+// withProgress 将job执行进度报告器写入执行上下文
+func (m *manager) withProgress(ctx context.Context, job JobIFace) context.Context {
+	return context.WithValue(ctx, progressContextKey{}, &jobProgress{manager: m, job: job})
+}
+
+// progressFromContext 从执行上下文获取job执行进度报告器
+func progressFromContext(ctx context.Context) Progress {
+	if progress, ok := ctx.Value(progressContextKey{}).(Progress); ok {
+		return progress
+	}
+	return nopProgress{}
+}
@@ -0,0 +1,45 @@
+/*
+ * @Time   : 2026/10/18 下午18:00
+ * @Email  : jjonline@jjonline.cn
+ */
+package queue
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// promotingQueue 测试用队列实现：需由消费端晋升延迟任务
+type promotingQueue struct {
+	QueueIFace
+	promoted int64
+}
+
+func (p *promotingQueue) PromoteDelayed(queue string) (count int, err error) {
+	atomic.AddInt64(&p.promoted, 1)
+	return 0, nil
+}
+
+// TestPromoterOnlyForPromotingDriver 仅需由消费端晋升延迟任务的队列实现开启晋升协程
+func TestPromoterOnlyForPromotingDriver(t *testing.T) {
+	task := &countTask{name: "promoter"}
+	q := newTestQueue(t, 1, task)
+	if _, ok := q.queue.(DelayedPromoter); ok {
+		t.Fatalf("memory driver promotes delayed jobs on pop and should not implement DelayedPromoter")
+	}
+
+	promoting := &promotingQueue{QueueIFace: q.queue}
+	q.queue = promoting
+	q.manager.queue = promoting
+	if err := q.SetPromoteInterval(10 * time.Millisecond); err != nil {
+		t.Fatalf("set promote interval: %v", err)
+	}
+	if err := q.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer q.ShutDown(context.Background())
+
+	waitFor(t, 5*time.Second, func() bool { return atomic.LoadInt64(&promoting.promoted) >= 3 })
+}
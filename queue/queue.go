@@ -8,6 +8,7 @@ import (
 	"context"
 	"fmt"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 	"sync"
 	"time"
 )
@@ -21,21 +22,36 @@ const (
 
 // Queue 队列struct
 type Queue struct {
-	queueBasic             // 引入队列基础方法
-	driver     string      // 记录底层队列实现
-	queue      QueueIFace  // 底层队列实现实体类，指针类型interface
-	manager    *manager    // 管理者对象实例
-	logger     *zap.Logger // 队列日志记录器，统一固定使用zap
+	queueBasic            // 引入队列基础方法
+	driver     string     // 记录底层队列实现
+	queue      QueueIFace // 底层队列实现实体类，指针类型interface
+	manager    *manager   // 管理者对象实例
+	logger     Logger     // 队列日志记录器
 }
 
 // New 初始化一个队列
-// 	@param driver     队列实现底层驱动，可选值见上方14行附近位置的常量
-// 	@param conn       driver对应底层驱动连接器句柄，具体类型参考 QueueIFace 实体类
-// 	@param logger     zap日志组件实例
-// 	@param concurrent 单个队列最大并发消费数
+//
+//	@param driver     队列实现底层驱动，可选值见上方14行附近位置的常量
+//	@param conn       driver对应底层驱动连接器句柄，具体类型参考 QueueIFace 实体类
+//	@param logger     zap日志组件实例
+//	@param concurrent 单个队列最大并发消费数，小于等于0时使用 AutoConcurrency 按CPU数自动计算
 func New(driver string, conn interface{}, logger *zap.Logger, concurrent int64) *Queue {
+	return NewWithLogger(driver, conn, NewZapLogger(logger), concurrent)
+}
+
+// NewWithLogger 使用自定义日志记录器初始化一个队列，日志组件非zap时使用
+//
+//	@param driver     队列实现底层驱动，可选值见上方14行附近位置的常量
+//	@param conn       driver对应底层驱动连接器句柄，具体类型参考 QueueIFace 实体类
+//	@param logger     日志记录器，可使用 NewZapLogger 适配zap或 NopLogger 不输出日志
+//	@param concurrent 单个队列最大并发消费数，小于等于0时使用 AutoConcurrency 按CPU数自动计算
+func NewWithLogger(driver string, conn interface{}, logger Logger, concurrent int64) *Queue {
 	var queue QueueIFace
 
+	if logger == nil {
+		logger = NopLogger()
+	}
+
 	// init specify queue driver
 	switch driver {
 	case Memory:
@@ -53,12 +69,15 @@ func New(driver string, conn interface{}, logger *zap.Logger, concurrent int64)
 		panic(err.Error())
 	}
 
-	return &Queue{
+	q := &Queue{
 		driver:  driver,
 		queue:   queue,
 		manager: newManager(queue, logger, concurrent),
 		logger:  logger,
 	}
+	// 周期任务由消费者投递，与生产者投递的job使用相同的编码、超时等设置
+	q.manager.basic = &q.queueBasic
+	return q
 }
 
 // region 处理失败任务Failed相关方法
@@ -67,8 +86,399 @@ func New(driver string, conn interface{}, logger *zap.Logger, concurrent int64)
 // 1、尝试了指定的最大尝试次数后仍然失败的任务善后方法
 // 2、此时通过此处设置的处理器可记录到底哪个任务失败了以及失败任务的payload参数情况
 // 3、以及后续的重试等逻辑等
+// 4、替换此前设置、添加的全部失败任务处理器，传nil则清空
 func (q *Queue) SetFailedJobHandler(failedJobHandler FailedJobHandler) {
-	q.manager.failedJobHandler = failedJobHandler
+	q.manager.failedJobHandlers = nil
+	q.AddFailedJobHandler(failedJobHandler)
+}
+
+// AddFailedJobHandler 添加失败任务的收尾处理器，用于同时发送告警、记录数据库、采集指标等
+// 1、多个处理器按添加顺序依次调用，某个处理器返回error或panic时记录日志后继续调用后续处理器
+// 2、需在 Start 之前添加
+func (q *Queue) AddFailedJobHandler(failedJobHandler FailedJobHandler) {
+	if failedJobHandler != nil {
+		q.manager.failedJobHandlers = append(q.manager.failedJobHandlers, failedJobHandler)
+	}
+}
+
+// SetFailedRecordHandler 设置失败任务记录处理器
+// 1、与 SetFailedJobHandler 触发时机相同，可同时设置
+// 2、任务类实现了 FailureRecorder 则传入其自定义的失败记录，否则传入失败job的 *Payload
+// 3、需在 Start 之前设置
+func (q *Queue) SetFailedRecordHandler(handler FailedRecordHandler) {
+	q.manager.failedRecordHandler = handler
+}
+
+// SetCodec 设置任务参数编解码器，例如使用 GzipCodec 压缩较大的任务参数以节省redis存储空间
+// 1、投递时长度不小于minSize的任务参数被编码，执行前自动解码，任务类无需任何改动
+// 2、生产者、消费者需设置相同的编解码器，未设置编解码器的消费者无法执行已编码的job，此类job按最终失败处理
+// 3、失败任务处理器、死信队列中的job任务参数保持编码状态，编码名称见 Payload.Encoding
+// @param codec   编解码器，传nil则不编码
+// @param minSize 最小编码长度，单位：字节
+func (q *Queue) SetCodec(codec Codec, minSize int) {
+	q.queueBasic.codec = codec
+	q.queueBasic.codecMinSize = minSize
+	q.manager.codec = codec
+}
+
+// SetPayloadCodec 设置job序列化器，默认使用json，例如替换为msgpack以减小job体积、与其他语言的系统共享队列
+// 1、投递、取出、再次投递、死信队列等读写job的场景均使用该序列化器，生产者、消费者需设置相同的序列化器
+// 2、redis驱动取出job的lua脚本需在服务端读写job字段，仅支持 PayloadFormatJSON、PayloadFormatMsgpack 格式，其他格式返回error
+// 3、仅可在 Start 之前设置，队列已启动时返回 ErrQueueStarted；切换序列化器前需确保队列中已无旧格式的job
+// @param codec job序列化器，传nil恢复为json
+func (q *Queue) SetPayloadCodec(codec PayloadCodec) error {
+	if err := q.manager.setPayloadCodec(codec); err != nil {
+		return err
+	}
+	q.queueBasic.payloadCodec = codec
+	return nil
+}
+
+// SetIDGenerator 设置投递job时的ID生成方法，默认使用UUID
+// 1、可按业务主键生成确定性的ID便于跨系统幂等，ID同时用于结果存储、执行中job查询等按ID关联的场景
+// 2、相同ID的job投递时并不去重，均会写入队列；需在投递时丢弃重复job请使用 WithUniqueFor
+// 3、同一消费者进程中相同ID的job已在执行时再次取出的job视为执行中重复job，按 SetDuplicatePolicy 设置的策略处理，默认稍后再次投递
+// 4、周期任务（见 ScheduledTask）由消费者投递，不使用该方法
+// 5、仅影响当前实例投递的job，需在投递之前设置
+func (q *Queue) SetIDGenerator(generator IDGenerator) {
+	q.queueBasic.idGenerator = generator
+}
+
+// SetDuplicatePolicy 设置执行中重复job的处理策略
+// 1、job执行超过超时时长仍未结束时，同一job可能被再次取出，此时由该策略决定本次取出job的处理动作
+// 2、未设置时默认作为延迟任务再次投递，可能导致重复执行，需任务类自主实现业务逻辑幂等
+// 3、不能容忍重复执行的任务可返回 DuplicateDrop 丢弃本次job
+func (q *Queue) SetDuplicatePolicy(policy DuplicatePolicy) {
+	q.manager.duplicatePolicy = policy
+}
+
+// SetExecuteLimit 设置全局执行并发数：所有队列同一时刻最多执行limit个job，与worker数量相互独立
+// 1、用于限制对共享外部资源的并发占用，例如worker数为100但同一时刻最多20个job写数据库
+// 2、执行名额已满时按action处理：ExecuteLimitWait 占用worker等待，ExecuteLimitRedeliver 稍后再次投递且不消耗尝试次数
+// 3、需在 Start 之前设置，队列已启动时返回 ErrQueueStarted
+func (q *Queue) SetExecuteLimit(limit int64, action ExecuteLimitAction) error {
+	return q.manager.setExecuteLimit(limit, action)
+}
+
+// SetLooperInterval 设置looper在所有队列均无job时的休眠间隔范围，默认450毫秒至1秒
+// 1、休眠间隔从最小值开始随机递增，超过最大值后回到最小值
+// 2、延迟敏感的队列可调小例如10毫秒至50毫秒，代价是空闲时底层驱动的轮询次数增加
+// 3、需在 Start 之前设置，队列已启动时返回 ErrQueueStarted
+func (q *Queue) SetLooperInterval(min, max time.Duration) error {
+	return q.manager.setLooperInterval(min, max)
+}
+
+// SetPromoteInterval 设置延迟任务晋升扫描间隔，默认1秒
+// 1、仅队列底层实现了 DelayedPromoter 时生效：消费者启动后按该间隔将到期的延迟任务晋升到待执行队列
+// 2、间隔越小延迟任务执行时刻越精确，底层存储的扫描开销越大
+// 3、需在 Start 之前设置，队列已启动时返回 ErrQueueStarted
+func (q *Queue) SetPromoteInterval(interval time.Duration) error {
+	return q.manager.setPromoteInterval(interval)
+}
+
+// SetStrictStart 设置严格启动模式（默认关闭）
+// 1、没有任何已注册任务类时启动消费者只会空转，几乎总是忘记注册任务类导致
+// 2、默认仅记录告警日志后继续启动，开启严格模式后 Start 返回 ErrNoTasksRegistered
+// 3、需在 Start 之前设置，队列已启动时返回 ErrQueueStarted
+func (q *Queue) SetStrictStart(strict bool) error {
+	return q.manager.setStrictStart(strict)
+}
+
+// SetForceRequeueOnShutdown 设置优雅关闭超时后是否将执行中的job放回队列（默认关闭）
+// 1、默认关闭时，ShutDown 等待超时后执行中的job仅能等待保留队列超时后再次投递
+// 2、开启后等待超时时将任务类执行中的job以0延迟放回队列且不消耗尝试次数，重启后立即再次取出执行
+// 3、被放回的job此后执行结束时不再处理其执行结果，任务类仍需实现业务逻辑幂等
+func (q *Queue) SetForceRequeueOnShutdown(force bool) {
+	q.manager.forceRequeue = force
+}
+
+// SetShutdownQuietPeriod 设置优雅关闭的静默等待时长：所有worker均已空闲后 ShutDown 再等待该时长才返回
+// 1、用于任务类在 Execute 中启动了脱离执行流程的协程（fire-and-forget），Execute 返回后协程仍在运行的场景
+// 2、静默等待只是兜底，无法感知这些协程是否真正结束；任务类应尽量不脱离执行流程启动协程，需要时在 Execute 内等待其结束
+// 3、静默等待期间ctx结束时 ShutDown 返回ctx的error，此时已没有执行中的job
+// 4、需在 Start 之前设置，队列已启动时返回 ErrQueueStarted
+//
+//	@param period 静默等待时长，默认0为不等待
+func (q *Queue) SetShutdownQuietPeriod(period time.Duration) error {
+	return q.manager.setQuietPeriod(period)
+}
+
+// SetBackoffStrategy 设置job执行失败可重试时的重试间隔策略
+// 1、未设置时使用 LinearBackoff 即每次均使用任务类设置的重试间隔，可选 ExponentialBackoff 指数退避
+// 2、底层驱动延迟精度为秒，策略返回的不足1秒的部分向上取整
+func (q *Queue) SetBackoffStrategy(strategy BackoffStrategy) {
+	q.manager.backoffStrategy = strategy
+}
+
+// SetReserveTimeout 设置保留超时时长：判定执行中的同ID job已失去响应的时长，与job执行超时时长相互独立
+// 1、同一job仍在执行中时又被取出，按 DuplicateRedeliver 再次投递时以此为延迟，默认为job的重试间隔
+// 2、按 DuplicateWait 等待执行中的job结束时以此为最长等待时长，默认为job的执行超时时长
+// 3、底层驱动的可见性超时与执行超时不一致时（例如类SQS驱动）按驱动的可见性超时设置
+// 4、需在 Start 之前设置，队列已启动时返回 ErrQueueStarted
+//
+//	@param timeout 保留超时时长，0为使用上述默认值
+func (q *Queue) SetReserveTimeout(timeout time.Duration) error {
+	return q.manager.setReserveTimeout(timeout)
+}
+
+// SetCompletedDedup 设置已执行成功job的去重窗口：窗口期内同ID的job再次被取出时直接删除不再执行
+// 1、用于防止底层驱动将已确认删除的job再次投递导致重复执行，执行中的同ID job仍按 SetDuplicatePolicy 处理
+// 2、仅记录当前实例执行成功的job，最多记录max个，超出时淘汰最早执行成功的记录，过期记录在读写时自动清理
+// 3、使用 SetIDGenerator 生成确定性ID时，窗口期内以相同ID再次投递的job同样会被丢弃
+// 4、需在 Start 之前设置，队列已启动时返回 ErrQueueStarted
+// @param ttl 去重窗口时长，0为不启用
+// @param max 最多记录的job数量，启用时需大于0
+func (q *Queue) SetCompletedDedup(ttl time.Duration, max int) error {
+	return q.manager.setCompletedDedup(ttl, max)
+}
+
+// SetRetryJitter 设置job放回重试延迟的随机抖动百分比，默认0即不抖动
+// 1、重试延迟按重试间隔策略计算后在 [-percent%, +percent%] 范围内随机抖动，避免下游故障时同时失败的大量job同时重试
+// 2、底层驱动延迟精度为秒，抖动后不足1秒的部分向上取整，重试延迟较小时抖动效果有限
+// 3、需在 Start 之前设置，队列已启动时返回 ErrQueueStarted
+//
+//	@param percent 抖动百分比，取值0-100
+func (q *Queue) SetRetryJitter(percent int) error {
+	return q.manager.setRetryJitter(percent)
+}
+
+// Use 注册任务类执行中间件，包装所有任务类的 Execute 实现日志、链路追踪、指标等横切逻辑
+// 1、多个中间件按注册顺序执行，先注册的位于最外层
+// 2、中间件内的panic与任务类执行panic一致按执行失败处理
+// 3、需在 Start 之前注册，队列已启动时返回 ErrQueueStarted
+func (q *Queue) Use(middlewares ...Middleware) error {
+	return q.manager.use(middlewares...)
+}
+
+// SetMetricsCollector 设置指标采集器，采集取出、执行、重试、最终失败等job生命周期指标
+// 1、Prometheus实现见 queueprom 子模块，传nil则不采集
+// 2、需在 Start 之前设置，队列已启动时返回 ErrQueueStarted
+func (q *Queue) SetMetricsCollector(collector MetricsCollector) error {
+	return q.manager.setMetricsCollector(collector)
+}
+
+// SetDeadLetterQueue 设置死信队列名称后缀，启用死信队列（默认不启用）
+// 1、job尝试次数耗尽最终失败时，删除之前将完整payload投递到 队列名称+后缀 的死信队列，例如后缀 ":dead"
+// 2、死信队列不会被自动消费，可使用 SizeByName 查看积压，需要重放时注册以死信队列名称为 Name 的任务类消费
+// 3、投递到死信队列的job尝试次数已重置，重放时拥有完整的尝试次数；消费死信队列时再次最终失败的job不再投递到死信队列
+// 4、传空字符串则关闭死信队列
+func (q *Queue) SetDeadLetterQueue(suffix string) {
+	q.manager.deadLetterSuffix = suffix
+}
+
+// OnQueueEmpty 设置队列由非空变为空时的回调，可用于所有分片job执行后触发下游汇总等扇入场景
+// 1、looper取出到job之后再次取出时队列已无可执行job即触发，每次由非空变为空仅触发一次
+// 2、队列为空仅表示已无可立即取出的job，最后取出的job可能仍在执行中，延迟中的job也不计入
+// 3、回调在looper协程中同步执行，不得阻塞或执行耗时操作，否则会延缓所有队列的job取出；需异步处理时请在回调中自行启动协程
+// 4、回调panic被捕获并记录日志，不影响looper继续取出job
+func (q *Queue) OnQueueEmpty(handler func(name string)) {
+	q.manager.onQueueEmpty = handler
+}
+
+// SetPanicHandler 设置任务类执行panic时的处理方法，用于将panic值及调用栈上报到Sentry等错误追踪平台
+// 1、默认仅记录包含调用栈的错误日志，设置后仍会记录日志
+// 2、处理方法执行完毕后job仍按执行失败进入重试或最终失败流程，处理方法自身的panic会被捕获并记录日志
+// 3、处理方法在执行job的协程中同步执行，耗时操作请自行异步处理
+func (q *Queue) SetPanicHandler(handler PanicHandler) {
+	q.manager.panicHandler = handler
+}
+
+// SetProgressSink 设置job执行进度接收方法，接收实现了 ProgressAware 的任务类执行时报告的进度
+// 1、通常按 job.Payload().ID 持久化进度，供业务界面查询展示长时间执行任务的进度
+// 2、接收方法在执行job的协程中同步调用，其panic会被捕获并记录日志
+func (q *Queue) SetProgressSink(sink ProgressSink) {
+	q.manager.progressSink = sink
+}
+
+// SetResultStore 设置job执行结果存储，保存实现了 ResultTask 的任务类执行成功的返回结果
+// 1、结果在删除job之前按jobID保存，可供web请求按投递时的jobID轮询异步计算的结果
+// 2、保存失败仅记录日志，不影响job执行成功的判定
+func (q *Queue) SetResultStore(store ResultStore) {
+	q.manager.resultStore = store
+}
+
+// SetPanicNormalizer 设置任务类执行panic值的规范化方法
+// 1、默认error类型的panic值原样使用，其他类型使用 fmt.Errorf("%v", recovered) 转换
+// 2、自定义panic类型可通过该方法提取类型信息、展开内部error等以便更好的排查问题
+// 3、规范化后的error用于记录日志以及传递给重试、失败任务处理器
+func (q *Queue) SetPanicNormalizer(normalizer PanicNormalizer) {
+	q.manager.panicNormalizer = normalizer
+}
+
+// OnLongRunning 设置job执行时长超过其超时时长时的回调，可用于针对性的告警慢依赖等问题
+// 1、job执行前、执行失败后检查执行时长，超过超时时长即触发，同一job单次取出执行期间仅触发一次
+// 2、elapsed为本次取出至检查时刻的时长
+// 3、回调在worker协程中同步执行，请勿执行耗时操作；回调panic被捕获并记录日志
+func (q *Queue) OnLongRunning(handler func(job JobIFace, elapsed time.Duration)) {
+	q.manager.onLongRunning = handler
+}
+
+// OnJobOutcome 设置job处理结果回调，用于审计每个取出的job的最终处理决定
+// 1、执行成功删除、放回重试、最终失败、再次投递、丢弃 均在做出决定处触发，详见 JobOutcome
+// 2、执行中重复job按等待策略等待成功后继续执行的，以本次执行的处理结果为准
+// 3、回调在worker或looper协程中同步执行，请勿执行耗时操作；回调panic被捕获并记录日志
+func (q *Queue) OnJobOutcome(handler JobOutcomeHandler) {
+	q.manager.onJobOutcome = handler
+}
+
+// Events 订阅job状态变化事件流，用于审计、调试等工具无需解析日志即可获取job的执行过程
+// 1、首次调用后才开始发送事件，多次调用返回同一通道，多个接收方将分摊事件而非各自收到全部事件
+// 2、事件以非阻塞方式发送，通道缓冲（见 JobEventBufferSize）已满时丢弃新事件，不影响job执行
+// 3、通道不会被关闭，接收方需自行结束接收
+func (q *Queue) Events() <-chan JobEvent {
+	return q.manager.subscribeEvents()
+}
+
+// OnShutdownProgress 设置优雅关闭等待期间的进度回调，便于记录关闭进度、排查关闭超时原因
+// 1、ShutDown 每次检查时触发，remaining为当前实例仍在执行中的job数量
+// 2、ShutDown 返回nil之前最后一次触发的remaining必然为0
+func (q *Queue) OnShutdownProgress(handler func(remaining int)) {
+	q.manager.onShutdownProgress = handler
+}
+
+// endregion
+
+// region 队列设置相关方法
+
+// SetBackendClock 设置是否使用队列底层驱动的时钟计算延迟任务执行时刻（默认关闭，使用本机时钟）
+// 1、生产者、消费者多机部署且时钟同步不精确时，开启后延迟任务以底层驱动时钟为准，执行时刻更准确
+// 2、redis驱动开启后每次投递延迟任务、每次取出任务均额外增加1次TIME命令的网络往返，请按需开启
+// 3、DelayAt 指定的执行时刻按原值使用，仅 Delay 等相对时长的延迟基于底层驱动时钟计算
+// 4、memory驱动生产者、消费者同进程，本身即使用同一时钟，设置无效果
+// 5、生产者、消费者需同时开启
+// 6、job payload 中的 EnqueuedAt、AvailableAt 时间戳始终按投递方本机时钟记录，不受该设置影响
+func (q *Queue) SetBackendClock(enable bool) {
+	if clock, ok := q.queue.(backendClockAware); ok {
+		clock.setBackendClock(enable)
+	}
+}
+
+// SetClock 替换队列底层驱动计算延迟任务、重试间隔以及保留超时所用的时钟，用于测试中快进时间
+// 1、仅memory驱动支持，其他驱动返回error
+// 2、需在 Start 之前设置
+func (q *Queue) SetClock(clock func() time.Time) error {
+	aware, ok := q.queue.(clockAware)
+	if !ok {
+		return fmt.Errorf("queue driver %s do not support custom clock", q.driver)
+	}
+	aware.setClock(clock)
+	return nil
+}
+
+// SetComponentLogLevel 按组件设置最低日志级别，例如looper日志仅输出warn以上而job执行日志输出info以上
+// 1、基于初始化时传入的日志记录器派生带级别过滤的子日志记录器，仅能在其原有级别基础上提高级别，无法降低
+// 2、需在 Start 之前设置
+// @param component 日志组件，可选值见 LogComponent 常量
+// @param level     该组件最低日志级别
+func (q *Queue) SetComponentLogLevel(component LogComponent, level zapcore.Level) error {
+	return q.manager.setComponentLogLevel(component, level)
+}
+
+// SetConcurrent 设置单个队列最大并发消费数，用于配置重载后无需重新实例化队列即可调整
+// 1、仅在 Start 之前设置有效，队列已启动时返回 ErrQueueStarted 且不做任何变更
+// 2、运行中的队列调整worker数量需使用动态伸缩能力
+// @param concurrent 并发消费数，需大于0且大于为快速队列预留的worker数量
+func (q *Queue) SetConcurrent(concurrent int64) error {
+	return q.manager.setConcurrent(concurrent)
+}
+
+// SetUnregisteredPolicy 设置找不到已注册任务类的job的处理策略
+// 1、此类job稍后再次投递且不消耗尝试次数，默认延迟1分钟且不限制次数，适用于新增队列的消费者滚动部署期间
+// 2、maxRetries大于0时再次投递超过该次数后按最终失败处理，进入死信队列、失败任务处理器，错误为 ErrTaskNotRegistered
+// 3、需在 Start 之前设置，队列已启动时返回 ErrQueueStarted
+func (q *Queue) SetUnregisteredPolicy(delay time.Duration, maxRetries int64) error {
+	return q.manager.setUnregisteredPolicy(delay, maxRetries)
+}
+
+// SetDriftThreshold 设置job投递延迟告警阈值，默认0不告警
+// 1、投递延迟为job首次被取出时刻与计划可被执行时刻（立即执行的job为投递时刻）的差值，底层驱动延迟精度为秒
+// 2、投递延迟超过阈值时记录告警日志，用于诊断队列负载较高时job延后执行的问题
+// 3、指标采集器实现了 DeliveryLatencyCollector 时采集所有首次取出job的投递延迟，与阈值无关
+func (q *Queue) SetDriftThreshold(threshold time.Duration) {
+	q.manager.driftThreshold = threshold
+}
+
+// SetJobTimeout 设置job默认超时时长以及超时时长上限
+// 1、job超时时长取自任务类 Timeout 方法，按秒存储，不足1秒或返回0时使用默认超时时长，默认 DefaultMaxExecuteDuration
+// 2、maxTimeout大于0时job超时时长不超过该上限，用于约束任务类设置过长的超时时长，0为不限制
+// 3、任务类超时时长不足1秒的job投递时按默认超时时长存储，长时间执行的job不会因保留超时被重复取出执行
+// 4、需在 Start 之前设置，队列已启动时返回 ErrQueueStarted
+func (q *Queue) SetJobTimeout(defaultTimeout, maxTimeout time.Duration) error {
+	if err := q.manager.setJobTimeout(defaultTimeout, maxTimeout); err != nil {
+		return err
+	}
+	q.queueBasic.jobTimeout = defaultTimeout
+	return nil
+}
+
+// SetMaxExecuteDuration 设置job最大执行时长，默认 DefaultMaxExecuteDuration
+// 1、任务类 Timeout 返回0或不足1秒（例如嵌入 DefaultTaskSettingWithoutTimeout）的job使用该时长作为执行超时时长以及执行时长超限告警阈值
+// 2、投递时按该时长记录job的保留时长，执行时长超过默认值的job调大该值后不会因保留超时被重复取出执行，生产者端需同样设置
+// 3、即 SetJobTimeout 的默认超时时长，已设置的超时时长上限保持不变；d必须大于0且不超过已设置的上限
+// 4、需在 Start 之前设置，队列已启动时返回 ErrQueueStarted
+func (q *Queue) SetMaxExecuteDuration(d time.Duration) error {
+	if err := q.manager.setMaxExecuteDuration(d); err != nil {
+		return err
+	}
+	q.queueBasic.jobTimeout = d
+	return nil
+}
+
+// SetChannelBuffer 设置looper投递job到worker的通道缓冲大小，默认0即无缓冲，worker空闲时才投递
+// 1、突发流量场景下少量缓冲可减少looper等待worker接收的阻塞，平滑吞吐
+// 2、缓冲中的job已从底层队列取出处于保留状态，其超时时长自取出时开始计算，等待执行的时长计入其中
+// 3、优雅关闭时缓冲中的job仍会被worker取出执行，需计入关闭等待时长；进程崩溃时缓冲中的job待保留超时后再次投递
+// 4、仅作用于普通队列通道，快速队列通道始终无缓冲；需在 Start 之前设置，队列已启动时返回 ErrQueueStarted
+//
+//	@param size 缓冲大小，按批计数，见 SetPopBatchSize
+func (q *Queue) SetChannelBuffer(size int) error {
+	return q.manager.setChannelBuffer(size)
+}
+
+// SetPopBatchSize 设置looper每次从单个队列取出的批数，默认1
+// 1、底层驱动实现了 BatchPopper 时一次网络往返取出多个job，redis驱动已支持，未实现时逐个取出
+// 2、取出的多批job依次投递给worker，排在后面的job需等待空闲worker，其超时时长自取出时开始计算，不宜设置过大
+// 3、需在 Start 之前设置，队列已启动时返回 ErrQueueStarted
+func (q *Queue) SetPopBatchSize(size int) error {
+	return q.manager.setPopBatchSize(size)
+}
+
+// SetLoopers 设置looper协程数量，默认1个
+// 1、单个looper串行的从所有队列取出job，队列数量多且底层驱动较慢时取出job会成为瓶颈
+// 2、多个looper按队列名称哈希划分各自负责的队列并行取出job，共用同一组worker
+// 3、队列数量较少时哈希划分可能不均匀，looper数量不宜超过队列数量
+// 4、需在 Start 之前设置，队列已启动时返回 ErrQueueStarted
+func (q *Queue) SetLoopers(loopers int) error {
+	return q.manager.setLoopers(loopers)
+}
+
+// Scale 运行时调整worker数量，可配合 QueueSize 等监控指标实现自动扩缩容
+// 1、扩容立即启动新的worker；缩容通知部分worker执行完当前job后退出，不中断执行中的job
+// 2、为快速队列预留的worker不参与缩容，worker数量需大于预留数量
+// 3、未启动时等同于 SetConcurrent，优雅关闭中返回 ErrQueueClosed
+func (q *Queue) Scale(concurrent int64) error {
+	return q.manager.scale(concurrent)
+}
+
+// SetFastQueues 设置快速队列并为其预留部分worker，避免慢任务占满所有worker导致快速任务排队阻塞
+// 1、预留的worker仅执行快速队列的job，其余worker执行所有队列的job
+// 2、快速/慢速由使用方按任务执行耗时自行划分，通常将执行耗时低于某个阈值的队列设为快速队列
+// 3、需在 Start 之前设置
+// @param names   快速队列名称
+// @param workers 为快速队列预留的worker数量，需大于0且小于并发数
+func (q *Queue) SetFastQueues(names []string, workers int64) error {
+	return q.manager.setFastQueues(names, workers)
+}
+
+// SetDedicatedWorkers 为指定队列设置专用worker池，与普通worker完全隔离，避免慢任务与其他任务互相阻塞
+// 1、专用worker在 SetConcurrent 设置的普通worker之外额外启动，仅执行该队列的job，该队列的job也仅由专用worker执行
+// 2、与 SetFastQueues 同时设置了同一队列时以专用worker池为准；专用worker不参与 Scale 伸缩
+// 3、任务类旧名称（见 AliasTask）对应的队列需使用旧名称单独设置
+// 4、需在 Start 之前设置，队列已启动时返回 ErrQueueStarted；重复设置同一队列时覆盖
+// @param name    队列名称
+// @param workers 专用worker数量，需大于0
+func (q *Queue) SetDedicatedWorkers(name string, workers int64) error {
+	return q.manager.setDedicatedWorkers(name, workers)
 }
 
 // endregion
@@ -76,17 +486,51 @@ func (q *Queue) SetFailedJobHandler(failedJobHandler FailedJobHandler) {
 // region 注册任务类相关方法
 
 // BootstrapOne boot注册载入一个队列任务
-//  @param task 任务类实例指针
+//
+//	@param task 任务类实例指针
 func (q *Queue) BootstrapOne(task TaskIFace) error {
 	return q.manager.bootstrapOne(task)
 }
 
 // BootstrapOne boot注册载入多个队列任务
-//  @tasks 任务类实例指针切片
+//
+//	@tasks 任务类实例指针切片
 func (q *Queue) Bootstrap(tasks []TaskIFace) error {
 	return q.manager.bootstrap(tasks)
 }
 
+// Register 注册一个队列任务类，启动前后均可调用，用于插件等在运行时加载任务类的场景
+// 1、启动后注册的任务类自looper下一轮遍历起开始取出job，无需暂停或重启队列
+// 2、重复注册同名任务类时替换原任务类，执行中的job仍由原任务类执行至结束
+// 3、实现了 ScheduledTask 的任务类仅在启动之前注册才会被周期投递
+func (q *Queue) Register(task TaskIFace) error {
+	return q.manager.bootstrapOne(task)
+}
+
+// Deregister 注销一个队列任务类及其旧名称（见 AliasTask），启动前后均可调用
+// 1、执行中的job继续执行至结束，队列中剩余的job保留在队列中不再被取出，重新注册后继续消费
+// 2、已取出尚未开始执行的job按找不到任务类处理，见 SetUnregisteredPolicy
+// 3、任务类未注册时返回error
+// @param name 任务类名称
+func (q *Queue) Deregister(name string) error {
+	return q.manager.deregister(name)
+}
+
+// RegisterFunc 以执行方法注册一个队列任务，适用于无需实现完整任务类的简单任务
+// 1、返回包装而成的任务类，生产者端投递job时使用
+// 2、最大尝试次数、重试间隔、超时时长未指定时与 DefaultTaskSetting 一致
+//
+//	@param name 队列名称
+//	@param fn   执行方法：执行成功返回nil，执行失败返回error
+//	@param opts 可选项：WithMaxTries、WithRetryInterval、WithTimeout
+func (q *Queue) RegisterFunc(name string, fn ExecuteFunc, opts ...FuncTaskOption) (TaskIFace, error) {
+	task := newFuncTask(name, fn, opts)
+	if err := q.manager.bootstrapOne(task); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
 // endregion
 
 // region 队列消费端相关方法
@@ -103,23 +547,218 @@ func (q *Queue) ShutDown(ctx context.Context) error {
 	return q.manager.shutDown(ctx)
 }
 
+// RunUntilSignal 启动队列消费者并阻塞直至收到SIGINT、SIGTERM信号后自动优雅停止，省去手动处理退出信号
+// 1、启动失败时直接返回启动错误
+// 2、收到信号后以timeout为等待上限优雅停止，返回值同 ShutDown
+// 3、期间通过 ShutDown、Drain 等其他方式关闭队列时不再等待信号直接返回nil，关闭结果由其调用方处理
+// @param timeout 优雅停止的等待时长
+func (q *Queue) RunUntilSignal(timeout time.Duration) error {
+	return q.manager.runUntilSignal(timeout)
+}
+
+// ProcessUntil 启动队列消费者运行指定时长后自动优雅停止，适用于限定运行时长的临时消费进程
+// 1、阻塞运行直至运行时长到达或ctx提前结束（外部取消视为提前停止），随后开始优雅停止
+// 2、优雅停止的等待上限为ctx截止时刻的剩余时长，ctx未设置截止时刻则等待执行中的job结束
+// 3、返回值同 ShutDown
+// @param ctx      外部控制上下文，建议设置为进程允许运行的截止时刻
+// @param duration 持续消费的时长
+func (q *Queue) ProcessUntil(ctx context.Context, duration time.Duration) error {
+	return q.manager.processUntil(ctx, duration)
+}
+
+// Drain 持续消费直至所有已注册任务类的队列为空且worker均空闲后自动优雅停止，适用于跑完即退出的批处理、定时消费进程
+// 1、队列未启动时先启动，启动失败时直接返回启动错误
+// 2、队列中延迟中的job、等待重试间隔的job均需执行结束才视为排空；暂停取出job期间不会排空
+// 3、ctx结束时不再等待排空：停止取出新job，等待执行中的job按各自超时时长结束后返回ctx的错误；排空后返回值同 ShutDown
+// @param ctx 外部控制上下文，建议设置为进程允许运行的截止时刻
+func (q *Queue) Drain(ctx context.Context) error {
+	return q.manager.drain(ctx)
+}
+
+// Pause 暂停取出job，用于发布或维护窗口期临时停止消费
+// 1、worker协程不退出，执行中的job继续执行至结束
+// 2、暂停期间looper不再从底层队列取出job，调用 Resume 恢复
+func (q *Queue) Pause() {
+	q.manager.pause()
+}
+
+// Resume 恢复取出job
+func (q *Queue) Resume() {
+	q.manager.resume()
+}
+
+// Reload 不退出进程以新的任务类集合替换已注册的任务类，用于配置热加载
+// 1、暂停取出job，等待已取出的job全部执行结束后替换任务类集合，再恢复取出；调用前已 Pause 时替换后保持暂停
+// 2、替换时不存在执行中的job，被移除任务类的job留在队列中，再次注册该任务类后继续执行
+// 3、ctx取消（等待执行中的job超时）或任务类名称冲突时返回error，已注册的任务类保持不变
+// 4、周期任务调度器随之同步：被移除的周期任务不再投递，新增的周期任务开始投递；替换期间不宜并发调用 Pause、Resume 以及按名称投递的方法
+//
+//	@param ctx   控制等待执行中job结束的最长时长
+//	@param tasks 新的任务类集合
+func (q *Queue) Reload(ctx context.Context, tasks []TaskIFace) error {
+	return q.manager.reload(ctx, tasks)
+}
+
+// IsPaused 检查队列是否处于暂停取出job状态
+func (q *Queue) IsPaused() bool {
+	return q.manager.paused.isSet()
+}
+
+// Cancel 取消当前实例执行中的指定job，用于终止失控的job
+// 1、取消该job执行时的ctx，任务类需响应ctx取消才能真正退出执行；不响应时worker不再等待其结果
+// 2、被取消的job不再重试，以 ErrJobCanceled 按最终失败处理；任务类仍返回nil视为执行成功
+// 3、仅作用于当前实例，job不在执行中（未取出、已结束或在其他实例执行）时返回false
+// @param jobID job的ID，可通过 RunningJobs 查询
+func (q *Queue) Cancel(jobID string) bool {
+	return q.manager.cancelJob(jobID)
+}
+
+// WaitInFlight 阻塞等待调用时刻当前实例执行中的job全部执行结束，可作为热替换共享依赖前的屏障
+// 1、不暂停取出新job，调用之后才开始执行的job不在等待范围内
+// 2、ctx结束时停止等待并返回ctx的错误
+func (q *Queue) WaitInFlight(ctx context.Context) error {
+	return q.manager.waitInFlight(ctx)
+}
+
+// Healthy 检查队列是否健康，用于Kubernetes等就绪、存活探针
+// 1、尚未启动、处于优雅关闭中（或已关闭）时返回false，使实例仅在消费者运行期间处于就绪状态
+// 2、设置了卡死判定时长时，所有worker均在执行且状态无变化超过该时长返回false
+func (q *Queue) Healthy() bool {
+	return q.manager.healthy()
+}
+
+// SetStuckWindow 设置worker卡死判定时长，默认0不检查
+// 1、所有worker均在执行job且状态（开始、结束执行job）无变化超过该时长时 Healthy 返回false，需大于任务类正常执行时长
+// 2、需在 Start 之前设置，队列已启动时返回 ErrQueueStarted
+func (q *Queue) SetStuckWindow(window time.Duration) error {
+	return q.manager.setStuckWindow(window)
+}
+
+// IsShuttingDown 检查队列是否处于优雅关闭中（或已关闭）状态
+// 1、可用于健康检查报告未就绪，或同进程内的生产者在投递任务前检查以停止生产
+// 2、关闭中（或已关闭）通过 Dispatch、Delay 等方法投递任务返回 ErrQueueClosed，未启动消费的纯生产者进程不受影响
+func (q *Queue) IsShuttingDown() bool {
+	return q.manager.shuttingDown()
+}
+
+// endregion
+
+// region 运行状态查询相关方法
+
+// Tasks 获取已注册的任务类名称列表，按名称排序
+func (q *Queue) Tasks() []string {
+	return q.manager.taskNames()
+}
+
+// RegisteredTasks 获取已注册的任务类配置信息，按名称排序
+// 可用于管理接口展示，或启动时校验期望的队列均已注册
+func (q *Queue) RegisteredTasks() []TaskInfo {
+	return q.manager.registeredTasks()
+}
+
+// RunningJobs 获取当前实例执行中的job快照，不包含其他消费者进程执行中的job
+func (q *Queue) RunningJobs() []RunningJob {
+	return q.manager.runningJobs()
+}
+
+// PartitionInFlight 获取当前实例各队列各分区执行中的job数量，用于排查分区并发控制问题
+// 返回值为 队列名称 => 分区键 => 执行中job数量 的副本
+func (q *Queue) PartitionInFlight() map[string]map[string]int64 {
+	return q.manager.partitionInFlightSnapshot()
+}
+
+// LatencyStats 获取当前实例指定队列最近执行成功job的执行时长p50/p95/p99分位数
+// 1、基于最近 LatencyWindowSize 个样本计算，无需额外的指标采集后端，适用于小规模部署快速查看
+// 2、执行时长自job取出时刻开始计算，含等待执行名额的时长；无样本时返回零值
+// @param name 队列名称
+func (q *Queue) LatencyStats(name string) LatencyStats {
+	return q.manager.latencyStats(name)
+}
+
+// Stats 获取当前实例运行状态快照，可定期采集用于监控面板
+func (q *Queue) Stats() Stats {
+	return q.manager.stats()
+}
+
+// ExecuteInFlight 获取当前实例全局执行并发名额的占用情况
+// 返回值为 执行中占用名额的job数量、全局执行并发数，未设置全局执行并发数时均为0
+func (q *Queue) ExecuteInFlight() (inFlight, limit int64) {
+	return q.manager.executeInFlight()
+}
+
+// Config 获取队列生效中的配置快照，排查队列运行表现时使用
+// 返回值为拷贝，修改返回值不影响队列实际配置
+func (q *Queue) Config() Config {
+	return q.manager.config()
+}
+
 // endregion
 
 // region 投递任务相关方法
 
-// Dispatch 投递一个队列Job任务
-func (q *Queue) Dispatch(task TaskIFace, payload interface{}) error {
-	queuePayload, err := q.marshalPayload(task, payload)
+// Dispatch 投递一个队列Job任务，队列处于优雅关闭中（或已关闭）时拒绝投递并返回 ErrQueueClosed
+// @param opts 投递任务可选项，例如 WithAvailableAt 指定任务可被执行的时刻
+func (q *Queue) Dispatch(task TaskIFace, payload interface{}, opts ...DispatchOption) error {
+	if q.manager.shuttingDown() {
+		return ErrQueueClosed
+	}
+
+	options := newDispatchOptions(opts)
+	availableAt := time.Now()
+	if options.availableAt.After(availableAt) {
+		availableAt = options.availableAt
+	}
+	priority := clampPriority(task, options.priority)
+	queuePayload, err := q.marshalPayload(task, payload, options.headers, availableAt, priority)
 	if nil != err {
 		return fmt.Errorf("queue %s job param marshal failed: %s", task.Name(), err.Error())
 	}
 
-	return q.queue.Push(task.Name(), queuePayload)
+	// 指定了优先级的job投递到对应优先级的子队列
+	name := q.priorityName(task.Name(), priority)
+	if options.uniqueKey != "" {
+		return q.dispatchUnique(task, name, queuePayload, options)
+	}
+	if !options.availableAt.IsZero() {
+		if delay := time.Until(options.availableAt); delay > 0 {
+			return q.queue.Later(name, delay, queuePayload)
+		}
+	}
+
+	return q.queue.Push(name, queuePayload)
 }
 
-// DelayAt 投递一个延迟队列Job任务
+// dispatchUnique 投递唯一job
+// @param name 投递的队列名称，指定了优先级时为优先级子队列名称
+func (q *Queue) dispatchUnique(task TaskIFace, name string, queuePayload []byte, options *dispatchOptions) error {
+	if !options.availableAt.IsZero() {
+		return fmt.Errorf("queue %s unique job do not support delayed", task.Name())
+	}
+	if options.uniqueFor <= 0 {
+		return fmt.Errorf("queue %s unique job window must be greater than 0", task.Name())
+	}
+	pusher, ok := q.queue.(UniquePusher)
+	if !ok {
+		return fmt.Errorf("queue driver %s do not support unique job", q.driver)
+	}
+
+	pushed, err := pusher.PushUnique(name, options.uniqueKey, queuePayload, options.uniqueFor)
+	if err != nil {
+		return err
+	}
+	if !pushed {
+		return ErrDuplicateJob
+	}
+	return nil
+}
+
+// DelayAt 投递一个延迟队列Job任务，队列处于优雅关闭中（或已关闭）时返回 ErrQueueClosed
 func (q *Queue) DelayAt(task TaskIFace, payload interface{}, delay time.Time) error {
-	queuePayload, err := q.marshalPayload(task, payload)
+	if q.manager.shuttingDown() {
+		return ErrQueueClosed
+	}
+
+	queuePayload, err := q.marshalPayload(task, payload, nil, delay, 0)
 	if nil != err {
 		return fmt.Errorf("queue %s job param marshal failed: %s", task.Name(), err.Error())
 	}
@@ -127,9 +766,13 @@ func (q *Queue) DelayAt(task TaskIFace, payload interface{}, delay time.Time) er
 	return q.queue.LaterAt(task.Name(), delay, queuePayload)
 }
 
-// Delay 投递一个延迟队列Job任务
+// Delay 投递一个延迟队列Job任务，队列处于优雅关闭中（或已关闭）时返回 ErrQueueClosed
 func (q *Queue) Delay(task TaskIFace, payload interface{}, duration time.Duration) error {
-	queuePayload, err := q.marshalPayload(task, payload)
+	if q.manager.shuttingDown() {
+		return ErrQueueClosed
+	}
+
+	queuePayload, err := q.marshalPayload(task, payload, nil, time.Now().Add(duration), 0)
 	if nil != err {
 		return fmt.Errorf("queue %s job param marshal failed: %s", task.Name(), err.Error())
 	}
@@ -140,20 +783,24 @@ func (q *Queue) Delay(task TaskIFace, payload interface{}, duration time.Duratio
 // DispatchByName 按任务name投递一个队列Job任务
 // 投递一个异步立即执行的任务
 // 重要:使用该方法则意味着投递任务之前必须bootstrap任务类，新项目请尽量使用DelayAt方法
-func (q *Queue) DispatchByName(name string, payload interface{}) error {
-	task, exist := q.manager.tasks[name]
+func (q *Queue) DispatchByName(name string, payload interface{}, opts ...DispatchOption) error {
+	if len(opts) == 0 {
+		return q.manager.Dispatch(name, payload)
+	}
+
+	task, exist := q.manager.registeredTask(name)
 	if !exist {
 		return fmt.Errorf("queue %s do not bootstrap", name)
 	}
 
-	return q.Dispatch(task, payload)
+	return q.Dispatch(task, payload, opts...)
 }
 
 // DelayAtByName 按任务name投递一个延迟队列Job任务
 // 投递一个异步延迟执行的任务
 // 重要提示:使用该方法则意味着投递任务之前必须bootstrap任务类，新项目请尽量使用DelayAt方法
 func (q *Queue) DelayAtByName(name string, payload interface{}, delay time.Time) error {
-	task, exist := q.manager.tasks[name]
+	task, exist := q.manager.registeredTask(name)
 	if !exist {
 		return fmt.Errorf("queue %s do not bootstrap", name)
 	}
@@ -161,13 +808,92 @@ func (q *Queue) DelayAtByName(name string, payload interface{}, delay time.Time)
 	return q.DelayAt(task, payload, delay)
 }
 
-// Size 获取指定队列当前长度
+// DispatchAfter 按任务name投递一个延迟指定时长执行的队列Job任务，delay不大于0时立即执行
+// 投递一个异步延迟执行的任务，job的最大尝试次数、重试间隔、超时时长取自已注册的任务类
+// 重要提示:使用该方法则意味着投递任务之前必须bootstrap任务类，新项目请尽量使用Delay方法
+func (q *Queue) DispatchAfter(name string, delay time.Duration, payload interface{}) error {
+	return q.manager.DispatchAfter(name, delay, payload)
+}
+
+// RequeueAll 将指定队列所有保留中（已取出执行中或执行中断）的job强制放回队列等待执行，返回放回的job数量
+// 1、用于故障恢复，例如修复了导致任务卡住的bug后立即重新执行这些job而无需等待其超时
+// 2、当前实例正在执行中的job会被跳过，但其他消费者进程正在执行的job无法感知，可能导致重复执行，需任务类自主实现业务逻辑幂等
+// 3、任务类实现了 PriorityTask 时一并放回各优先级子队列保留中的job
+// @param name 队列名称
+func (q *Queue) RequeueAll(name string) (int, error) {
+	return q.manager.requeueAll(name)
+}
+
+// QueueSize 按状态分别获取指定队列待执行、延迟中、保留中的job数量，可用于积压告警、自动扩缩容
+// 1、无需任务类已注册，可用于统计死信队列等
+// 2、队列底层驱动未实现 QueueSizer 时返回error
+// 3、任务类实现了 PriorityTask 时为各优先级子队列之和
+func (q *Queue) QueueSize(name string) (QueueSize, error) {
+	sizer, ok := q.queue.(QueueSizer)
+	if !ok {
+		return QueueSize{}, fmt.Errorf("queue driver %s do not support queue size by state", q.driver)
+	}
+
+	var total QueueSize
+	for _, queue := range q.manager.priorityQueueNames(name) {
+		size, err := sizer.QueueSize(queue)
+		if err != nil {
+			return QueueSize{}, err
+		}
+		total.Pending += size.Pending
+		total.Delayed += size.Delayed
+		total.Reserved += size.Reserved
+	}
+	return total, nil
+}
+
+// Peek 按执行顺序查看指定队列最多n个待执行job的payload，不取出job，不影响执行中的job
+// 1、无需任务类已注册，用于管理界面查看即将执行的job；不含延迟中、保留中的job
+// 2、队列底层驱动未实现 Peeker 时返回error
+// 3、任务类实现了 PriorityTask 时自高优先级子队列起依次查看
+// @param name 队列名称
+// @param n    最多获取的job数量
+func (q *Queue) Peek(name string, n int) ([]Payload, error) {
+	peeker, ok := q.queue.(Peeker)
+	if !ok {
+		return nil, fmt.Errorf("queue driver %s do not support peek", q.driver)
+	}
+
+	payloads := make([]Payload, 0)
+	for _, queue := range q.manager.priorityQueueNames(name) {
+		if len(payloads) >= n {
+			break
+		}
+		items, err := peeker.Peek(queue, n-len(payloads))
+		if err != nil {
+			return nil, err
+		}
+		payloads = append(payloads, items...)
+	}
+	return payloads, nil
+}
+
+// SizeByName 按任务name获取指定队列当前长度，任务类未注册返回0
+func (q *Queue) SizeByName(name string) int64 {
+	task, exist := q.manager.registeredTask(name)
+	if !exist {
+		return 0
+	}
+	return q.Size(task)
+}
+
+// Size 获取指定队列当前长度，任务类实现了 PriorityTask 时为各优先级子队列长度之和
 func (q *Queue) Size(task TaskIFace) int64 {
-	if _, exist := q.manager.tasks[task.Name()]; !exist {
+	if _, exist := q.manager.registeredTask(task.Name()); !exist {
 		// 确保队列任务以注册
 		return 0
 	}
-	return q.queue.Size(task.Name())
+
+	var size int64
+	for priority := 0; priority < priorityLevels(task); priority++ {
+		size += q.queue.Size(q.priorityName(task.Name(), priority))
+	}
+	return size
 }
 
 // endregion
@@ -5,11 +5,22 @@
 package queue
 
 import (
-	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
 )
 
+// priorityInfix 优先级子队列名称中队列名称与优先级之间的分隔
+const priorityInfix = ":priority:"
+
 // queueBasic 队列基础公用方法
-type queueBasic struct{}
+type queueBasic struct {
+	idGenerator  IDGenerator   // job ID生成方法，为nil时使用UUID
+	codec        Codec         // 任务参数编解码器，为nil时不编码
+	codecMinSize int           // 任务参数最小编码长度，小于该长度的任务参数不编码
+	jobTimeout   time.Duration // job默认超时时长，为0时使用 DefaultMaxExecuteDuration
+	payloadCodec PayloadCodec  // job序列化器，为nil时使用json
+}
 
 // region 获取队列相关名称私有方法
 
@@ -28,28 +39,126 @@ func (r *queueBasic) delayedName(queue string) string {
 	return queue + ":delayed"
 }
 
+// priorityName 获取队列指定优先级的子队列名称：0级及以下即为原队列
+func (r *queueBasic) priorityName(queue string, priority int) string {
+	return priorityQueueName(queue, priority)
+}
+
+// uniqueName 获取队列唯一job唯一键名称
+func (r *queueBasic) uniqueName(queue, key string) string {
+	return queue + ":unique:" + key
+}
+
 // marshalPayload 初始化创建生成队列内部存储的payload字符串
 // @task	  队列任务类实例
 // @taskParam 队列job参数
-// @ID	      队列job编号ID（延迟队列）
-func (r *queueBasic) marshalPayload(task TaskIFace, taskParam interface{}) ([]byte, error) {
-	return json.Marshal(Payload{
+// @headers   队列job元数据头，可为nil
+// @availableAt 队列job计划可被执行的时刻
+// @priority  队列job优先级，见 PriorityTask
+func (r *queueBasic) marshalPayload(task TaskIFace, taskParam interface{}, headers map[string]string, availableAt time.Time, priority int) ([]byte, error) {
+	body := []byte(IFaceToString(taskParam))
+	id := r.jobID(task.Name(), body)
+	body, encoding, err := encodeBody(r.codec, r.codecMinSize, body)
+	if err != nil {
+		return nil, err
+	}
+	return r.marshal(Payload{
 		Name:          task.Name(),
-		ID:            FakeUniqueID(),
+		ID:            id,
 		MaxTries:      task.MaxTries(),
 		RetryInterval: task.RetryInterval(),
 		Attempts:      0,
-		Payload:       []byte(IFaceToString(taskParam)),
-		PopTime:       0,                               // 首次被取出开始执行的时间戳，取出的时候才去设置
-		Timeout:       int64(task.Timeout().Seconds()), // 最大执行秒数
-		TimeoutAt:     0,                               // 超时时刻，被执行时刻才会去设置
+		Payload:       body,
+		PopTime:       0,                      // 首次被取出开始执行的时间戳，取出的时候才去设置
+		Timeout:       r.timeoutSeconds(task), // 最大执行秒数
+		TimeoutAt:     0,                      // 超时时刻，被执行时刻才会去设置
+		Headers:       headers,
+		AvailableAt:   availableAt.Unix(),
+		Encoding:      encoding,
+		EnqueuedAt:    time.Now().Unix(), // 首次投递时刻，按本机时钟记录
+		Priority:      priority,
 	})
 }
 
+// timeoutSeconds 任务类最大执行秒数：不足1秒时使用默认超时时长
+// 与 manager.jobTimeout 保持一致，避免保留job的超时时刻早于实际执行超时而被重复取出执行
+func (r *queueBasic) timeoutSeconds(task TaskIFace) int64 {
+	if seconds := int64(task.Timeout().Seconds()); seconds > 0 {
+		return seconds
+	}
+	if r.jobTimeout > 0 {
+		return int64(r.jobTimeout.Seconds())
+	}
+	return int64(DefaultMaxExecuteDuration.Seconds())
+}
+
+// jobID 生成job ID：设置了ID生成方法且返回值非空时使用其返回值，否则使用UUID
+func (r *queueBasic) jobID(name string, body []byte) string {
+	if r.idGenerator != nil {
+		if id := r.idGenerator(name, body); id != "" {
+			return id
+		}
+	}
+	return FakeUniqueID()
+}
+
+// marshal 序列化job为队列内部存储的payload字符串
+func (r *queueBasic) marshal(payload Payload) ([]byte, error) {
+	return payloadCodecOf(r.payloadCodec).Marshal(payload)
+}
+
 // unmarshalPayload 解析生成队列内部存储的payload字符串为struct
 // @payload 队列内部存储的payload字符串
 func (r *queueBasic) unmarshalPayload(payload []byte, result *Payload) error {
-	return json.Unmarshal(payload, result)
+	return payloadCodecOf(r.payloadCodec).Unmarshal(payload, result)
+}
+
+// priorityQueueName 获取队列指定优先级的子队列名称：0级及以下即为原队列
+func priorityQueueName(queue string, priority int) string {
+	if priority <= 0 {
+		return queue
+	}
+	return queue + priorityInfix + strconv.Itoa(priority)
+}
+
+// priorityBaseName 解析优先级子队列名称，返回原队列名称；非优先级子队列返回false
+func priorityBaseName(queue string) (string, bool) {
+	index := strings.LastIndex(queue, priorityInfix)
+	if index <= 0 {
+		return "", false
+	}
+	if priority, err := strconv.Atoi(queue[index+len(priorityInfix):]); err != nil || priority <= 0 {
+		return "", false
+	}
+	return queue[:index], true
+}
+
+// jobQueueName job所属的队列名称：优先级子队列中的job为原队列名称，用于指标、执行时长统计、死信队列命名等
+func jobQueueName(job JobIFace) string {
+	if base, isPriority := priorityBaseName(job.GetName()); isPriority {
+		return base
+	}
+	return job.GetName()
+}
+
+// clampPriority 按任务类优先级级数修正优先级：未实现 PriorityTask 的任务类始终为0
+func clampPriority(task TaskIFace, priority int) int {
+	levels := priorityLevels(task)
+	if priority <= 0 || levels <= 1 {
+		return 0
+	}
+	if priority >= levels {
+		return levels - 1
+	}
+	return priority
+}
+
+// priorityLevels 任务类优先级级数，未实现 PriorityTask 或返回值小于等于1时为1
+func priorityLevels(task TaskIFace) int {
+	if priorityTask, ok := task.(PriorityTask); ok && priorityTask.PriorityLevels() > 1 {
+		return priorityTask.PriorityLevels()
+	}
+	return 1
 }
 
 // endregion
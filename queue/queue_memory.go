@@ -10,6 +10,9 @@ import (
 	"time"
 )
 
+// memoryUniqueSweepMin 唯一键清理阈值的最小值
+const memoryUniqueSweepMin = 1024
+
 // itemValue 延迟map实现、原生链表 实体结构
 type itemValue struct {
 	Payload Payload // job参数载体
@@ -23,21 +26,59 @@ type memoryQueue struct {
 	list     map[string]*list.List            // 原生链表模拟queue队列
 	delayed  map[string]map[string]*itemValue // 使用map模拟延迟队列
 	reserved map[string]map[string]*itemValue // 使用map模拟延迟队列
+	unique   map[string]time.Time             // 唯一job唯一键与唯一窗口期截止时刻
+	sweepAt  int                              // 唯一键数量达到该值时清理已过期的唯一键
+	clock    func() time.Time                 // 时钟，未设置时使用本机时钟
 	lock     sync.Mutex
 }
 
 func (m *memoryQueue) Size(queue string) (size int64) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
 	m.lazyInit(queue)
 
 	return int64(m.list[queue].Len() + len(m.delayed[queue]) + len(m.reserved[queue]))
 }
 
+// QueueSize 按状态分别统计队列中的job数量
+// implement QueueSizer
+func (m *memoryQueue) QueueSize(queue string) (size QueueSize, err error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.lazyInit(queue)
+
+	return QueueSize{
+		Pending:  int64(m.list[queue].Len()),
+		Delayed:  int64(len(m.delayed[queue])),
+		Reserved: int64(len(m.reserved[queue])),
+	}, nil
+}
+
+// Peek 按执行顺序获取最多n个待执行job的payload，不取出job
+// implement Peeker
+func (m *memoryQueue) Peek(queue string, n int) (payloads []Payload, err error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.lazyInit(queue)
+
+	for element := m.list[queue].Front(); element != nil && len(payloads) < n; element = element.Next() {
+		payloads = append(payloads, element.Value.(*itemValue).Payload)
+	}
+	return payloads, nil
+}
+
 func (m *memoryQueue) Push(queue string, payload interface{}) (err error) {
 	var originPayload Payload
 	if err = m.unmarshalPayload(payload.([]byte), &originPayload); err != nil {
 		return err
 	}
 
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
 	m.lazyInit(queue)
 
 	item := &itemValue{
@@ -49,8 +90,53 @@ func (m *memoryQueue) Push(queue string, payload interface{}) (err error) {
 	return nil
 }
 
+// PushUnique 唯一窗口期内同一唯一键仅投递一次
+// implement UniquePusher
+func (m *memoryQueue) PushUnique(queue string, key string, payload interface{}, ttl time.Duration) (pushed bool, err error) {
+	var originPayload Payload
+	if err = m.unmarshalPayload(payload.([]byte), &originPayload); err != nil {
+		return false, err
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.lazyInit(queue)
+
+	now := m.now()
+	name := m.uniqueName(queue, key)
+	if expireAt, exist := m.unique[name]; exist && now.Before(expireAt) {
+		return false, nil
+	}
+	m.unique[name] = now.Add(ttl)
+	m.sweepUniqueLocked(now)
+
+	m.list[queue].PushBack(&itemValue{
+		Payload: originPayload,
+		TimeAt:  0,
+	})
+	return true, nil
+}
+
+// sweepUniqueLocked 唯一键数量达到清理阈值时清理已过期的唯一键，调用方需持有锁
+// 清理后阈值调整为剩余数量的2倍，均摊到每次投递为常数开销
+func (m *memoryQueue) sweepUniqueLocked(now time.Time) {
+	if len(m.unique) < m.sweepAt {
+		return
+	}
+	for uniqueName, expireAt := range m.unique {
+		if !now.Before(expireAt) {
+			delete(m.unique, uniqueName)
+		}
+	}
+	m.sweepAt = 2 * len(m.unique)
+	if m.sweepAt < memoryUniqueSweepMin {
+		m.sweepAt = memoryUniqueSweepMin
+	}
+}
+
 func (m *memoryQueue) Later(queue string, durationTo time.Duration, payload interface{}) (err error) {
-	return m.LaterAt(queue, time.Now().Add(durationTo), payload)
+	return m.LaterAt(queue, m.now().Add(durationTo), payload)
 }
 
 func (m *memoryQueue) LaterAt(queue string, timeAt time.Time, payload interface{}) (err error) {
@@ -59,6 +145,9 @@ func (m *memoryQueue) LaterAt(queue string, timeAt time.Time, payload interface{
 		return err
 	}
 
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
 	m.lazyInit(queue)
 
 	item := &itemValue{
@@ -72,31 +161,24 @@ func (m *memoryQueue) LaterAt(queue string, timeAt time.Time, payload interface{
 	return nil
 }
 
+// PopPriority 按优先级从高到低依次尝试从各优先级子队列取出1条待执行的任务
+// implement PriorityPopper
+func (m *memoryQueue) PopPriority(queue string, levels int) (job JobIFace, exist bool) {
+	for priority := levels - 1; priority >= 0; priority-- {
+		if job, exist = m.Pop(m.priorityName(queue, priority)); exist {
+			return job, true
+		}
+	}
+	return nil, false
+}
+
 func (m *memoryQueue) Pop(queue string) (job JobIFace, exist bool) {
 	m.lock.Lock()
 	defer m.lock.Unlock()
 
-	now := time.Now()
+	now := m.now()
 	// step1、调度延迟任务
-	if m.delayed[queue] != nil {
-		m.lazyInit(queue) // 延迟队列已初始化，但是保留队列可能未初始化
-		// 迭代每个延迟job
-		for id, item := range m.delayed[queue] {
-			if item.TimeAt <= now.Unix() {
-				// 执行时刻已到，将延迟任务丢到list
-				itemV := &itemValue{
-					Payload: item.Payload,
-					TimeAt:  0,
-				}
-
-				// delete from delay map
-				delete(m.delayed[queue], id)
-
-				// push to list
-				m.list[queue].PushBack(itemV)
-			}
-		}
-	}
+	m.promoteDelayed(queue, now)
 
 	// step2、处理保留重试任务
 	if m.reserved[queue] != nil {
@@ -149,6 +231,8 @@ func (m *memoryQueue) Pop(queue string) (job JobIFace, exist bool) {
 
 	// 转换值构造job
 	return &JobMemory{
+		lock:        &m.lock,
+		now:         m.now,
 		reserved:    m.reserved,
 		delayed:     m.delayed,
 		reservedJob: node.Payload,
@@ -161,13 +245,38 @@ func (m *memoryQueue) Pop(queue string) (job JobIFace, exist bool) {
 			isReleased: false,
 			isDeleted:  false,
 			hasFailed:  false,
-			popTime:    time.Unix(node.Payload.PopTime, 0),
+			popTime:    now, // 本次取出时刻，首次取出时刻见payload的PopTime字段
 			timeout:    time.Duration(payload.Timeout) * time.Second,
 			timeoutAt:  now.Add(time.Duration(payload.Timeout) * time.Second),
 		},
 	}, true
 }
 
+func (m *memoryQueue) RequeueReserved(queue string, skip func(id string) bool) (count int, err error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.lazyInit(queue)
+
+	for id, item := range m.reserved[queue] {
+		if skip != nil && skip(id) {
+			continue
+		}
+
+		// delete from reserved map
+		delete(m.reserved[queue], id)
+
+		// push to list
+		m.list[queue].PushBack(&itemValue{
+			Payload: item.Payload,
+			TimeAt:  0,
+		})
+		count++
+	}
+
+	return count, nil
+}
+
 func (m *memoryQueue) SetConnection(connection interface{}) (err error) {
 	// no code
 	return nil
@@ -178,6 +287,57 @@ func (m *memoryQueue) GetConnection() (connection interface{}, err error) {
 	return nil, nil
 }
 
+// promoteDelayed 将执行时刻已到的延迟任务移动到待执行list，调用方需持有锁
+// 取出job时即晋升到期的延迟任务，故memory驱动无需实现 DelayedPromoter 由消费端定期晋升
+func (m *memoryQueue) promoteDelayed(queue string, now time.Time) (count int) {
+	if m.delayed[queue] == nil {
+		return 0
+	}
+
+	m.lazyInit(queue) // 延迟队列已初始化，但是保留队列可能未初始化
+	// 迭代每个延迟job
+	for id, item := range m.delayed[queue] {
+		if item.TimeAt <= now.Unix() {
+			// 执行时刻已到，将延迟任务丢到list
+			itemV := &itemValue{
+				Payload: item.Payload,
+				TimeAt:  0,
+			}
+
+			// delete from delay map
+			delete(m.delayed[queue], id)
+
+			// push to list
+			m.list[queue].PushBack(itemV)
+			count++
+		}
+	}
+	return count
+}
+
+// setClock 替换时钟
+func (m *memoryQueue) setClock(clock func() time.Time) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.clock = clock
+}
+
+// setPayloadCodec 设置job序列化器，内存驱动直接存储解析后的job，支持任意序列化格式
+func (m *memoryQueue) setPayloadCodec(codec PayloadCodec) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.payloadCodec = codec
+	return nil
+}
+
+// now 当前时刻：设置了时钟则使用设置的时钟，否则使用本机时钟
+func (m *memoryQueue) now() time.Time {
+	if m.clock != nil {
+		return m.clock()
+	}
+	return time.Now()
+}
+
 func (m *memoryQueue) lazyInit(queue string) {
 	// lazy init map
 	if m.list == nil {
@@ -189,6 +349,9 @@ func (m *memoryQueue) lazyInit(queue string) {
 	if m.delayed == nil {
 		m.delayed = make(map[string]map[string]*itemValue)
 	}
+	if m.unique == nil {
+		m.unique = make(map[string]time.Time)
+	}
 
 	// lazy init map item
 	if _, exist := m.list[queue]; !exist {
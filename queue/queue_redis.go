@@ -7,6 +7,7 @@ package queue
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/go-redis/redis/v8"
 	"sync"
 	"time"
@@ -30,9 +31,10 @@ import (
 // redisQueue 基于Redis实现的队列
 // implement QueueIFace
 type redisQueue struct {
-	queueBasic               // 队列基础可公用方法
-	connection *redis.Client // connection redis客户端实例
-	luaScripts *luaScripts   // redis lua脚本生成器
+	queueBasic                    // 队列基础可公用方法
+	connection      *redis.Client // connection redis客户端实例
+	luaScripts      *luaScripts   // redis lua脚本生成器
+	useBackendClock bool          // 是否使用redis服务端时钟计算延迟执行时刻和取出时刻
 }
 
 // Size 获取队列长度
@@ -46,15 +48,68 @@ func (r *redisQueue) Size(queue string) (size int64) {
 	return result
 }
 
+// QueueSize 按状态分别统计队列中的job数量
+// implement QueueSizer
+func (r *redisQueue) QueueSize(queue string) (size QueueSize, err error) {
+	ctx := context.Background()
+	pipe := r.connection.Pipeline()
+	pending := pipe.LLen(ctx, r.name(queue))
+	delayed := pipe.ZCard(ctx, r.delayedName(queue))
+	reserved := pipe.ZCard(ctx, r.reservedName(queue))
+	if _, err = pipe.Exec(ctx); err != nil {
+		return size, err
+	}
+
+	return QueueSize{Pending: pending.Val(), Delayed: delayed.Val(), Reserved: reserved.Val()}, nil
+}
+
+// Peek 按执行顺序获取最多n个待执行job的payload，不取出job
+// implement Peeker
+func (r *redisQueue) Peek(queue string, n int) (payloads []Payload, err error) {
+	if n <= 0 {
+		return nil, nil
+	}
+
+	ctx := context.Background()
+	members, err := r.connection.LRange(ctx, r.name(queue), 0, int64(n-1)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	payloads = make([]Payload, 0, len(members))
+	for _, member := range members {
+		var payload Payload
+		if r.unmarshalPayload([]byte(member), &payload) != nil {
+			continue
+		}
+		payloads = append(payloads, payload)
+	}
+	return payloads, nil
+}
+
 // Push 投递一条任务到队列
 func (r *redisQueue) Push(queue string, payload interface{}) (err error) {
 	ctx := context.Background()
 	return r.connection.RPush(ctx, queue, payload).Err()
 }
 
+// PushUnique 唯一窗口期内同一唯一键仅投递一次
+// implement UniquePusher
+func (r *redisQueue) PushUnique(queue string, key string, payload interface{}, ttl time.Duration) (pushed bool, err error) {
+	ctx := context.Background()
+	result, err := r.luaScripts.PushUnique().Run(
+		ctx,
+		r.connection,
+		[]string{r.name(queue), r.uniqueName(queue, key)},
+		payload,
+		ttl.Milliseconds(),
+	).Int64()
+	return result == 1, err
+}
+
 // Later 延迟指定时长后执行的延迟任务
 func (r *redisQueue) Later(queue string, durationTo time.Duration, payload interface{}) (err error) {
-	return r.LaterAt(queue, time.Now().Add(durationTo), payload)
+	return r.LaterAt(queue, r.now().Add(durationTo), payload)
 }
 
 // LaterAt 指定时刻执行的延时任务
@@ -73,10 +128,96 @@ func (r *redisQueue) Pop(queue string) (job JobIFace, exist bool) {
 	// step2、处理失败重试任务：从保留有序集合（queueName:reserved）取出Score值小于等于当前时间戳的保留任务丢到List队列
 	// step3、调度list尝试执行：从list取出1条，将字段Attempts自增1，Score值为任务执行超时的时间戳，丢到保留有序集合（queueName:reserved）
 
-	now := time.Now()
+	now := r.now()
+
+	// step1 && step2、migrate expired delay and reserved zSet data to queue list
+	ctx := context.Background()
+	r.migrateExpiredJobs(ctx, queue, now)
+
+	// step3、get one item from queue list
+	ret3, err := r.popScript().Run(
+		ctx,
+		r.connection,
+		[]string{r.name(queue), r.reservedName(queue)}, // 从list移动到reserved的zSet
+		now.Unix(), // 当前时间戳，用于填充为0的首次取出时间（PopTime字段）
+	).Result()
+
+	if err != nil {
+		// redis pop lua execute error
+		return nil, false
+	}
+
+	return r.newJob(queue, now, ret3)
+}
+
+// PopPriority 按优先级从高到低依次尝试从各优先级子队列取出1条待执行的任务
+// implement PriorityPopper
+func (r *redisQueue) PopPriority(queue string, levels int) (job JobIFace, exist bool) {
+	for priority := levels - 1; priority >= 0; priority-- {
+		if job, exist = r.Pop(r.priorityName(queue, priority)); exist {
+			return job, true
+		}
+	}
+	return nil, false
+}
+
+// PopBatch 取出弹出多条待执行的任务：迁移到期的延迟、保留任务后取出最多n条
+// 1、首条job通过 Run 取出，脚本未缓存时自动加载；队列为空时直接返回
+// 2、其余job通过pipeline一次网络往返取出，pipeline中仅发送脚本sha（EVALSHA），不重复发送脚本内容
+// @param queue 队列的名称
+// @param n     最多取出的job数量
+// implement BatchPopper
+func (r *redisQueue) PopBatch(queue string, n int) (jobs []JobIFace, err error) {
+	now := r.now()
 
-	// step1、migrate expired delay zSet data to queue list
 	ctx := context.Background()
+	r.migrateExpiredJobs(ctx, queue, now)
+
+	script := r.popScript()
+	keys := []string{r.name(queue), r.reservedName(queue)}
+	ret, err := script.Run(ctx, r.connection, keys, now.Unix()).Result()
+	if err == redis.Nil {
+		// 队列为空时脚本返回nil即 redis.Nil，不视为错误
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if job, ok := r.newJob(queue, now, ret); ok {
+		jobs = append(jobs, job)
+	}
+	if n <= 1 {
+		return jobs, nil
+	}
+
+	pipe := r.connection.Pipeline()
+	cmds := make([]*redis.Cmd, 0, n-1)
+	for i := 1; i < n; i++ {
+		cmds = append(cmds, script.EvalSha(ctx, pipe, keys, now.Unix()))
+	}
+	if _, err = pipe.Exec(ctx); err == redis.Nil {
+		err = nil
+	}
+
+	for _, cmd := range cmds {
+		ret, cmdErr := cmd.Result()
+		if cmdErr != nil {
+			if cmdErr != redis.Nil && err == nil {
+				err = cmdErr
+			}
+			continue
+		}
+		if job, ok := r.newJob(queue, now, ret); ok {
+			jobs = append(jobs, job)
+		}
+	}
+
+	return jobs, err
+}
+
+// migrateExpiredJobs 将执行时刻已到的延迟任务以及执行超时的保留任务迁移到队列list
+func (r *redisQueue) migrateExpiredJobs(ctx context.Context, queue string, now time.Time) {
+	// migrate expired delay zSet data to queue list
 	r.luaScripts.MigrateExpiredJobs().Run(
 		ctx,
 		r.connection,
@@ -84,30 +225,23 @@ func (r *redisQueue) Pop(queue string) (job JobIFace, exist bool) {
 		now.Unix(),
 	)
 
-	// step2、migrate expired reserved zSet data to queue list
+	// migrate expired reserved zSet data to queue list
 	r.luaScripts.MigrateExpiredJobs().Run(
 		ctx,
 		r.connection,
 		[]string{r.reservedName(queue), r.name(queue)},
 		now.Unix(),
 	)
+}
 
-	// step3、get one item from queue list
-	ret3, err := r.luaScripts.Pop().Run(
-		ctx,
-		r.connection,
-		[]string{r.name(queue), r.reservedName(queue)}, // 从list移动到reserved的zSet
-		now.Unix(), // 当前时间戳，用于填充为0的首次取出时间（PopTime字段）
-	).Result()
-
-	if err != nil {
-		// redis pop lua execute error
-		return nil, false
-	}
-
+// newJob 由pop lua脚本的返回值构造job
+// @param queue 队列的名称
+// @param now   本次取出时刻
+// @param ret   pop lua脚本返回的job与保留job
+func (r *redisQueue) newJob(queue string, now time.Time, ret interface{}) (job JobIFace, exist bool) {
 	// set payload
-	jobAndReserved := ret3.([]interface{})
-	if len(jobAndReserved) != 2 {
+	jobAndReserved, ok := ret.([]interface{})
+	if !ok || len(jobAndReserved) != 2 {
 		// array result returned
 		return nil, false
 	}
@@ -140,13 +274,82 @@ func (r *redisQueue) Pop(queue string) (job JobIFace, exist bool) {
 			isReleased: false,
 			isDeleted:  false,
 			hasFailed:  false,
-			popTime:    time.Unix(reserved.PopTime, 0),
+			popTime:    now, // 本次取出时刻，首次取出时刻见payload的PopTime字段
 			timeout:    time.Duration(reserved.Timeout) * time.Second,
 			timeoutAt:  now.Add(time.Duration(reserved.Timeout) * time.Second),
 		},
 	}, true
 }
 
+// RequeueReserved 将保留有序集合中的job重新放回队列list等待执行
+// @param queue 队列名称
+// @param skip  返回true的jobID跳过不处理
+func (r *redisQueue) RequeueReserved(queue string, skip func(id string) bool) (count int, err error) {
+	ctx := context.Background()
+	members, err := r.connection.ZRange(ctx, r.reservedName(queue), 0, -1).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	for _, member := range members {
+		var payload Payload
+		if r.unmarshalPayload([]byte(member), &payload) != nil {
+			continue
+		}
+		if skip != nil && skip(payload.ID) {
+			continue
+		}
+
+		moved, err := r.luaScripts.Requeue().Run(
+			ctx,
+			r.connection,
+			[]string{r.reservedName(queue), r.name(queue)},
+			member,
+		).Int64()
+		if err != nil {
+			return count, err
+		}
+		count += int(moved)
+	}
+
+	return count, nil
+}
+
+// setPayloadCodec 设置job序列化器：取出job的lua脚本需在redis服务端读写job字段，仅支持json、msgpack格式
+func (r *redisQueue) setPayloadCodec(codec PayloadCodec) error {
+	if name := payloadCodecOf(codec).Name(); name != PayloadFormatJSON && name != PayloadFormatMsgpack {
+		return fmt.Errorf("queue driver redis do not support payload format %s", name)
+	}
+	r.payloadCodec = codec
+	return nil
+}
+
+// popScript 按job序列化格式获取取出job的lua脚本
+func (r *redisQueue) popScript() *redis.Script {
+	if payloadCodecOf(r.payloadCodec).Name() == PayloadFormatMsgpack {
+		return r.luaScripts.PopMsgpack()
+	}
+	return r.luaScripts.Pop()
+}
+
+// setBackendClock 设置是否使用redis服务端时钟
+func (r *redisQueue) setBackendClock(enable bool) {
+	r.useBackendClock = enable
+}
+
+// now 获取当前时刻：启用服务端时钟时通过redis TIME命令获取，获取失败降级为本机时钟
+func (r *redisQueue) now() time.Time {
+	if !r.useBackendClock {
+		return time.Now()
+	}
+
+	serverTime, err := r.connection.Time(context.Background()).Result()
+	if err != nil {
+		return time.Now()
+	}
+	return serverTime
+}
+
 // SetConnection
 // 设置redis队列的连接器：redis client句柄指针
 func (r *redisQueue) SetConnection(connection interface{}) (err error) {
@@ -157,9 +360,10 @@ func (r *redisQueue) SetConnection(connection interface{}) (err error) {
 // GetConnection
 // 获取redis队列的连接器：redis client句柄指针（interface）使用前需显式转换
 // example:
-// 		conn, _ := r.GetConnection()
-// 		client := conn.(*redis.Client)
-//		client.Set("key", "values")
+//
+//	conn, _ := r.GetConnection()
+//	client := conn.(*redis.Client)
+//	client.Set("key", "values")
 func (r *redisQueue) GetConnection() (connection interface{}, err error) {
 	if r.connection == nil {
 		return nil, errors.New("null pointer connection instance")
@@ -0,0 +1,238 @@
+/*
+ * @Time   : 2026/10/15 下午14:00
+ * @Email  : jjonline@jjonline.cn
+ */
+package queueadmin
+
+import (
+	"encoding/json"
+	"github.com/jjonline/go-lib-backend/queue"
+	"net/http"
+	"strconv"
+)
+
+// defaultPeekSize 查看待执行job时默认获取的数量
+const defaultPeekSize = 10
+
+// *************************************************
+// 队列管理http接口
+// 1、只读接口以json格式输出队列运行状态：已注册任务类及队列长度、执行中的job、是否关闭中
+// 2、写操作接口仅支持POST请求，且需通过初始化时传入的鉴权方法校验
+// 3、独立子包按需引入，挂载到任意路由前缀下使用：
+//    mux.Handle("/queue/", http.StripPrefix("/queue", queueadmin.NewHandler(service, auth)))
+// 4、管理接口外层套有鉴权中间件时，探针使用的健康检查接口需单独挂载到鉴权中间件之外：
+//    mux.Handle("/healthz", queueadmin.NewHealthzHandler(service))
+// *************************************************
+
+// AuthFunc 写操作接口鉴权方法：返回true表示允许执行
+type AuthFunc func(r *http.Request) bool
+
+// TaskStatus 已注册任务类状态
+type TaskStatus struct {
+	queue.TaskInfo       // 任务类配置信息
+	Size           int64 // 队列当前长度
+}
+
+// Status 队列整体状态
+type Status struct {
+	ShuttingDown bool // 是否处于优雅关闭中
+	Paused       bool // 是否暂停取出job
+}
+
+// handler 队列管理http接口实现
+type handler struct {
+	queue *queue.Queue
+	auth  AuthFunc
+	mux   *http.ServeMux
+}
+
+// NewHandler 实例化队列管理http接口
+// @param service 队列实例
+// @param auth    写操作接口鉴权方法，传nil则禁用所有写操作接口
+//
+// 接口列表：
+//
+//	GET  /healthz            健康检查，不健康时响应503，无需鉴权，见 NewHealthzHandler
+//	GET  /status             队列整体状态
+//	GET  /stats              当前实例运行状态快照
+//	GET  /tasks              已注册任务类配置及其队列长度
+//	GET  /running            当前实例执行中的job
+//	GET  /peek?name=队列名&n=10 按执行顺序查看指定队列待执行的job，不取出
+//	POST /requeue?name=队列名 将指定队列保留中的job放回队列重新执行
+//	POST /cancel?id=jobID    取消当前实例执行中的指定job
+//	POST /pause              暂停取出job
+//	POST /resume             恢复取出job
+func NewHandler(service *queue.Queue, auth AuthFunc) http.Handler {
+	h := &handler{queue: service, auth: auth, mux: http.NewServeMux()}
+
+	h.mux.HandleFunc("/healthz", probe(service))
+	h.mux.HandleFunc("/status", h.readOnly(h.status))
+	h.mux.HandleFunc("/stats", h.readOnly(h.stats))
+	h.mux.HandleFunc("/tasks", h.readOnly(h.tasks))
+	h.mux.HandleFunc("/running", h.readOnly(h.running))
+	h.mux.HandleFunc("/peek", h.readOnly(h.peek))
+	h.mux.HandleFunc("/requeue", h.writable(h.requeue))
+	h.mux.HandleFunc("/cancel", h.writable(h.cancel))
+	h.mux.HandleFunc("/pause", h.writable(h.pause))
+	h.mux.HandleFunc("/resume", h.writable(h.resume))
+
+	return h
+}
+
+// ServeHTTP implement http.Handler
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+// NewHealthzHandler 实例化健康检查http接口：不经任何鉴权，用于Kubernetes等就绪、存活探针
+// 队列未启动、关闭中或worker卡死时响应503，健康时响应200
+// @param service 队列实例
+func NewHealthzHandler(service *queue.Queue) http.Handler {
+	return probe(service)
+}
+
+// probe 健康检查：用于就绪、存活探针，不健康时响应503
+func probe(service *queue.Queue) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		if !service.Healthy() {
+			writeJSON(w, http.StatusServiceUnavailable, map[string]bool{"healthy": false})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]bool{"healthy": true})
+	}
+}
+
+// status 队列整体状态
+func (h *handler) status(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, Status{ShuttingDown: h.queue.IsShuttingDown(), Paused: h.queue.IsPaused()})
+}
+
+// stats 当前实例运行状态快照
+func (h *handler) stats(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.queue.Stats())
+}
+
+// tasks 已注册任务类配置及其队列长度
+func (h *handler) tasks(w http.ResponseWriter, r *http.Request) {
+	infos := h.queue.RegisteredTasks()
+	tasks := make([]TaskStatus, 0, len(infos))
+	for _, info := range infos {
+		tasks = append(tasks, TaskStatus{TaskInfo: info, Size: h.queue.SizeByName(info.Name)})
+	}
+	writeJSON(w, http.StatusOK, tasks)
+}
+
+// running 当前实例执行中的job
+func (h *handler) running(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.queue.RunningJobs())
+}
+
+// peek 按执行顺序查看指定队列待执行的job
+func (h *handler) peek(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		writeError(w, http.StatusBadRequest, "queue name is required")
+		return
+	}
+	n := defaultPeekSize
+	if raw := r.URL.Query().Get("n"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 {
+			writeError(w, http.StatusBadRequest, "n must be a positive integer")
+			return
+		}
+		n = parsed
+	}
+
+	payloads, err := h.queue.Peek(name, n)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, payloads)
+}
+
+// requeue 将指定队列保留中的job放回队列重新执行
+func (h *handler) requeue(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		writeError(w, http.StatusBadRequest, "queue name is required")
+		return
+	}
+
+	count, err := h.queue.RequeueAll(name)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]int{"count": count})
+}
+
+// cancel 取消当前实例执行中的指定job
+func (h *handler) cancel(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		writeError(w, http.StatusBadRequest, "job id is required")
+		return
+	}
+
+	if !h.queue.Cancel(id) {
+		writeError(w, http.StatusNotFound, "job is not running")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]bool{"canceled": true})
+}
+
+// pause 暂停取出job
+func (h *handler) pause(w http.ResponseWriter, r *http.Request) {
+	h.queue.Pause()
+	h.status(w, r)
+}
+
+// resume 恢复取出job
+func (h *handler) resume(w http.ResponseWriter, r *http.Request) {
+	h.queue.Resume()
+	h.status(w, r)
+}
+
+// readOnly 只读接口仅允许GET请求
+func (h *handler) readOnly(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		next(w, r)
+	}
+}
+
+// writable 写操作接口仅允许POST请求且需通过鉴权
+func (h *handler) writable(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		if h.auth == nil || !h.auth(r) {
+			writeError(w, http.StatusForbidden, "forbidden")
+			return
+		}
+		next(w, r)
+	}
+}
+
+// writeJSON 输出json响应
+func writeJSON(w http.ResponseWriter, code int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(data)
+}
+
+// writeError 输出json格式的错误响应
+func writeError(w http.ResponseWriter, code int, msg string) {
+	writeJSON(w, code, map[string]string{"error": msg})
+}
@@ -0,0 +1,48 @@
+/*
+ * @Time   : 2026/10/18 下午2:00
+ * @Email  : jjonline@jjonline.cn
+ */
+package queueadmin
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jjonline/go-lib-backend/queue"
+)
+
+// TestHealthzReadiness 健康检查无需鉴权，仅在队列启动后、关闭前响应200
+func TestHealthzReadiness(t *testing.T) {
+	service := queue.NewWithLogger(queue.Memory, nil, queue.NopLogger(), 1)
+	if _, err := service.RegisterFunc("healthz", func(ctx context.Context, job *queue.RawBody) error { return nil }); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	denyAll := func(r *http.Request) bool { return false }
+	handlers := map[string]http.Handler{
+		"admin":   NewHandler(service, denyAll),
+		"healthz": NewHealthzHandler(service),
+	}
+
+	check := func(stage string, expected int) {
+		t.Helper()
+		for name, handler := range handlers {
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+			if recorder.Code != expected {
+				t.Fatalf("%s %s: expected %d, got %d", stage, name, expected, recorder.Code)
+			}
+		}
+	}
+
+	check("before start", http.StatusServiceUnavailable)
+	if err := service.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	check("started", http.StatusOK)
+	if err := service.ShutDown(context.Background()); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+	check("shutdown", http.StatusServiceUnavailable)
+}
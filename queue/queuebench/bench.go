@@ -0,0 +1,173 @@
+/*
+ * @Time   : 2026/10/15 上午10:00
+ * @Email  : jjonline@jjonline.cn
+ */
+package queuebench
+
+import (
+	"context"
+	"errors"
+	"github.com/jjonline/go-lib-backend/queue"
+	"go.uber.org/zap"
+	"sort"
+	"sync"
+	"time"
+)
+
+// *************************************************
+// 队列基准测试辅助工具
+// 1、提供空操作任务类 NoopTask，仅记录job从投递到执行完毕的端到端延迟
+// 2、提供基准测试 Run：基于memory驱动走真实的队列调度流程，统计吞吐量与延迟分位值
+// 3、用于实际调整并发数等队列设置，memory驱动保证结果可复现
+// *************************************************
+
+// NoopTaskName 空操作任务类的队列名称
+const NoopTaskName = "queuebench_noop"
+
+// ErrNoJobs 基准测试job数量需大于0
+var ErrNoJobs = errors.New("queuebench.jobs.must.greater.than.zero")
+
+// NoopTask 空操作任务类：执行时不做任何业务处理，仅记录job投递到执行完毕的延迟
+type NoopTask struct {
+	queue.DefaultTaskSetting
+	lock      sync.Mutex
+	total     int             // 预期执行的job总数
+	latencies []time.Duration // 已执行job的端到端延迟
+	done      chan struct{}   // 全部job执行完毕信号
+}
+
+// NewNoopTask 实例化一个空操作任务类
+// @param total 预期执行的job总数，执行数量达到后关闭 Done 返回的chan
+func NewNoopTask(total int) *NoopTask {
+	return &NoopTask{
+		total:     total,
+		latencies: make([]time.Duration, 0, total),
+		done:      make(chan struct{}),
+	}
+}
+
+// Name 空操作任务类队列名称
+func (t *NoopTask) Name() string {
+	return NoopTaskName
+}
+
+// Execute 空操作执行：job参数为投递时刻的纳秒时间戳，记录投递到执行的延迟
+func (t *NoopTask) Execute(ctx context.Context, job *queue.RawBody) error {
+	latency := time.Since(time.Unix(0, job.Int64()))
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.latencies = append(t.latencies, latency)
+	if len(t.latencies) == t.total {
+		close(t.done)
+	}
+	return nil
+}
+
+// Done 全部job执行完毕信号chan
+func (t *NoopTask) Done() <-chan struct{} {
+	return t.done
+}
+
+// Latencies 已执行job的端到端延迟副本
+func (t *NoopTask) Latencies() []time.Duration {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	latencies := make([]time.Duration, len(t.latencies))
+	copy(latencies, t.latencies)
+	return latencies
+}
+
+// Options 基准测试参数
+type Options struct {
+	Jobs       int                // 投递的job数量
+	Concurrent int64              // 队列并发worker数
+	Setup      func(*queue.Queue) // 启动前对队列做额外设置，可选
+}
+
+// Result 基准测试结果
+type Result struct {
+	Jobs       int           // 执行完毕的job数量
+	Elapsed    time.Duration // 启动消费到全部job执行完毕的耗时
+	Throughput float64       // 吞吐量：每秒执行的job数
+	P50        time.Duration // 端到端延迟50分位值
+	P95        time.Duration // 端到端延迟95分位值
+	P99        time.Duration // 端到端延迟99分位值
+	Max        time.Duration // 端到端最大延迟
+}
+
+// Run 执行一次基准测试：投递全部job后启动消费，等待全部执行完毕或上下文结束
+// 上下文提前结束时返回已执行部分的统计结果以及上下文错误
+func Run(ctx context.Context, opts Options) (result Result, err error) {
+	if opts.Jobs <= 0 {
+		return result, ErrNoJobs
+	}
+	if opts.Concurrent <= 0 {
+		opts.Concurrent = 1
+	}
+
+	task := NewNoopTask(opts.Jobs)
+	service := queue.New(queue.Memory, nil, zap.NewNop(), opts.Concurrent)
+	if err = service.BootstrapOne(task); err != nil {
+		return result, err
+	}
+	if opts.Setup != nil {
+		opts.Setup(service)
+	}
+
+	for i := 0; i < opts.Jobs; i++ {
+		if err = service.Dispatch(task, time.Now().UnixNano()); err != nil {
+			return result, err
+		}
+	}
+
+	startAt := time.Now()
+	if err = service.Start(); err != nil {
+		return result, err
+	}
+
+	select {
+	case <-task.Done():
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	elapsed := time.Since(startAt)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_ = service.ShutDown(shutdownCtx)
+
+	return summarize(task.Latencies(), elapsed), err
+}
+
+// summarize 统计延迟分位值
+func summarize(latencies []time.Duration, elapsed time.Duration) Result {
+	result := Result{Jobs: len(latencies), Elapsed: elapsed}
+	if len(latencies) == 0 {
+		return result
+	}
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	if elapsed > 0 {
+		result.Throughput = float64(len(latencies)) / elapsed.Seconds()
+	}
+	result.P50 = percentile(latencies, 0.50)
+	result.P95 = percentile(latencies, 0.95)
+	result.P99 = percentile(latencies, 0.99)
+	result.Max = latencies[len(latencies)-1]
+	return result
+}
+
+// percentile 已排序延迟切片的分位值
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	idx := int(float64(len(sorted))*p+0.5) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	if idx >= len(sorted) {
+		idx = len(sorted) - 1
+	}
+	return sorted[idx]
+}
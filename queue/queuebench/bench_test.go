@@ -0,0 +1,52 @@
+/*
+ * @Time   : 2026/10/15 上午10:00
+ * @Email  : jjonline@jjonline.cn
+ */
+package queuebench
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestRun(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := Run(ctx, Options{Jobs: 100, Concurrent: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Jobs != 100 {
+		t.Fatalf("want 100 jobs executed, got %d", result.Jobs)
+	}
+	if result.P50 > result.P99 || result.P99 > result.Max {
+		t.Fatalf("percentiles out of order: %+v", result)
+	}
+}
+
+func TestRunNoJobs(t *testing.T) {
+	if _, err := Run(context.Background(), Options{}); err != ErrNoJobs {
+		t.Fatalf("want ErrNoJobs, got %v", err)
+	}
+}
+
+func BenchmarkRun(b *testing.B) {
+	for _, concurrent := range []int64{1, 8} {
+		b.Run(fmt.Sprintf("concurrent-%d", concurrent), func(b *testing.B) {
+			b.ResetTimer()
+			result, err := Run(context.Background(), Options{Jobs: b.N, Concurrent: concurrent})
+			b.StopTimer()
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			b.ReportMetric(result.Throughput, "jobs/s")
+			b.ReportMetric(float64(result.P50.Microseconds()), "p50-µs")
+			b.ReportMetric(float64(result.P95.Microseconds()), "p95-µs")
+			b.ReportMetric(float64(result.P99.Microseconds()), "p99-µs")
+		})
+	}
+}
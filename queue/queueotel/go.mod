@@ -0,0 +1,9 @@
+module github.com/jjonline/go-lib-backend/queue/queueotel
+
+go 1.16
+
+require (
+	github.com/jjonline/go-lib-backend/queue v0.0.0-20261015054417-4a0d825e9f95
+	go.opentelemetry.io/otel v0.20.0
+	go.opentelemetry.io/otel/trace v0.20.0
+)
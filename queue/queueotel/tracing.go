@@ -0,0 +1,128 @@
+/*
+ * @Time   : 2026/10/15 下午21:00
+ * @Email  : jjonline@jjonline.cn
+ */
+package queueotel
+
+import (
+	"context"
+	"github.com/jjonline/go-lib-backend/queue"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// *************************************************
+// 队列OpenTelemetry链路追踪
+// 1、投递端通过 Inject 将当前链路上下文写入job元数据头，执行端通过 Middleware 提取并以队列名称开启span
+// 2、未设置 WithTracerProvider 时使用noop实现，不产生任何span
+// 3、独立子模块按需引入，避免队列核心模块依赖opentelemetry
+//    _ = service.Use(queueotel.Middleware(queueotel.WithTracerProvider(tp)))
+//    _ = service.Dispatch(task, param, queueotel.Inject(ctx))
+// *************************************************
+
+const instrumentationName = "github.com/jjonline/go-lib-backend/queue/queueotel"
+
+// Option 链路追踪可选项
+type Option func(cfg *config)
+
+// config 链路追踪可选项集合
+type config struct {
+	tracerProvider trace.TracerProvider
+	propagator     propagation.TextMapPropagator
+}
+
+// newConfig 应用链路追踪可选项
+func newConfig(opts []Option) *config {
+	cfg := &config{
+		tracerProvider: trace.NewNoopTracerProvider(),
+		propagator:     propagation.TraceContext{},
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
+// WithTracerProvider 设置TracerProvider，未设置时不产生任何span
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(cfg *config) {
+		if provider != nil {
+			cfg.tracerProvider = provider
+		}
+	}
+}
+
+// WithPropagator 设置链路上下文传播器，默认W3C TraceContext
+func WithPropagator(propagator propagation.TextMapPropagator) Option {
+	return func(cfg *config) {
+		if propagator != nil {
+			cfg.propagator = propagator
+		}
+	}
+}
+
+// headerCarrier job元数据头适配 propagation.TextMapCarrier
+type headerCarrier map[string]string
+
+// Get implement propagation.TextMapCarrier
+func (c headerCarrier) Get(key string) string {
+	return c[key]
+}
+
+// Set implement propagation.TextMapCarrier
+func (c headerCarrier) Set(key string, value string) {
+	c[key] = value
+}
+
+// Keys implement propagation.TextMapCarrier
+func (c headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// Inject 将ctx中的链路上下文写入投递job的元数据头
+// 执行端需使用相同的传播器，ctx中没有链路上下文时不写入
+func Inject(ctx context.Context, opts ...Option) queue.DispatchOption {
+	carrier := headerCarrier{}
+	newConfig(opts).propagator.Inject(ctx, carrier)
+	return queue.WithHeaders(carrier)
+}
+
+// Middleware 链路追踪执行中间件
+// 1、从job元数据头提取投递端链路上下文，以队列名称为span名称开启consumer类型span
+// 2、任务类执行返回error时记录到span并标记span状态为失败
+func Middleware(opts ...Option) queue.Middleware {
+	cfg := newConfig(opts)
+	tracer := cfg.tracerProvider.Tracer(instrumentationName)
+
+	return func(next queue.ExecuteFunc) queue.ExecuteFunc {
+		return func(ctx context.Context, job *queue.RawBody) error {
+			ctx = cfg.propagator.Extract(ctx, headerCarrier(job.Headers()))
+			ctx, span := tracer.Start(
+				ctx,
+				job.Queue(),
+				trace.WithSpanKind(trace.SpanKindConsumer),
+				trace.WithAttributes(
+					attribute.String("messaging.system", "queue"),
+					attribute.String("messaging.destination", job.Queue()),
+					attribute.String("messaging.message_id", job.ID),
+				),
+			)
+			defer span.End()
+
+			err := next(ctx, job)
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			} else {
+				span.SetStatus(codes.Ok, "")
+			}
+			return err
+		}
+	}
+}
@@ -0,0 +1,141 @@
+/*
+ * @Time   : 2026/10/15 下午19:00
+ * @Email  : jjonline@jjonline.cn
+ */
+package queueprom
+
+import (
+	"github.com/jjonline/go-lib-backend/queue"
+	"github.com/prometheus/client_golang/prometheus"
+	"time"
+)
+
+// *************************************************
+// 队列Prometheus指标采集器
+// 1、实现 queue.MetricsCollector，按队列名称label输出取出、执行成功、重试、最终失败计数以及执行时长直方图
+// 2、独立子模块按需引入，避免队列核心模块依赖prometheus
+//    collector := queueprom.NewCollector("app")
+//    prometheus.MustRegister(collector)
+//    _ = service.SetMetricsCollector(collector)
+// *************************************************
+
+// Collector 队列Prometheus指标采集器
+// implement queue.MetricsCollector, queue.DeliveryLatencyCollector and prometheus.Collector
+type Collector struct {
+	popped    *prometheus.CounterVec
+	processed *prometheus.CounterVec
+	retried   *prometheus.CounterVec
+	failed    *prometheus.CounterVec
+	duration  *prometheus.HistogramVec
+	latency   *prometheus.HistogramVec
+}
+
+// latencyBuckets 投递延迟直方图分桶，单位秒
+var latencyBuckets = []float64{0.5, 1, 2, 5, 10, 30, 60, 120, 300}
+
+// NewCollector 实例化队列Prometheus指标采集器，使用默认直方图分桶
+// @param namespace 指标命名空间，指标名称形如 namespace_queue_jobs_processed_total
+func NewCollector(namespace string) *Collector {
+	return NewCollectorWithBuckets(namespace, prometheus.DefBuckets)
+}
+
+// NewCollectorWithBuckets 实例化队列Prometheus指标采集器，自定义执行时长直方图分桶
+// @param namespace 指标命名空间
+// @param buckets   执行时长直方图分桶，单位秒
+func NewCollectorWithBuckets(namespace string, buckets []float64) *Collector {
+	labels := []string{"queue"}
+	return &Collector{
+		popped: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "queue",
+			Name:      "jobs_popped_total",
+			Help:      "Total number of jobs popped from queue.",
+		}, labels),
+		processed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "queue",
+			Name:      "jobs_processed_total",
+			Help:      "Total number of jobs executed successfully.",
+		}, labels),
+		retried: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "queue",
+			Name:      "jobs_retried_total",
+			Help:      "Total number of failed jobs released for retry.",
+		}, labels),
+		failed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "queue",
+			Name:      "jobs_failed_total",
+			Help:      "Total number of jobs failed after exhausting attempts.",
+		}, labels),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "queue",
+			Name:      "job_execute_duration_seconds",
+			Help:      "Duration from job popped to execute finished.",
+			Buckets:   buckets,
+		}, []string{"queue", "status"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "queue",
+			Name:      "job_delivery_latency_seconds",
+			Help:      "Latency from job scheduled run time to first popped.",
+			Buckets:   latencyBuckets,
+		}, labels),
+	}
+}
+
+// JobPopped implement queue.MetricsCollector
+func (c *Collector) JobPopped(queue string, count int) {
+	c.popped.WithLabelValues(queue).Add(float64(count))
+}
+
+// JobExecuted implement queue.MetricsCollector
+func (c *Collector) JobExecuted(queue string, duration time.Duration, err error) {
+	status := "success"
+	if err != nil {
+		status = "error"
+	} else {
+		c.processed.WithLabelValues(queue).Inc()
+	}
+	c.duration.WithLabelValues(queue, status).Observe(duration.Seconds())
+}
+
+// JobRetried implement queue.MetricsCollector
+func (c *Collector) JobRetried(queue string, delay time.Duration) {
+	c.retried.WithLabelValues(queue).Inc()
+}
+
+// JobFailed implement queue.MetricsCollector
+func (c *Collector) JobFailed(queue string, err error) {
+	c.failed.WithLabelValues(queue).Inc()
+}
+
+// JobDeliveryLatency implement queue.DeliveryLatencyCollector
+func (c *Collector) JobDeliveryLatency(queue string, latency time.Duration) {
+	c.latency.WithLabelValues(queue).Observe(latency.Seconds())
+}
+
+// Describe implement prometheus.Collector
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.popped.Describe(ch)
+	c.processed.Describe(ch)
+	c.retried.Describe(ch)
+	c.failed.Describe(ch)
+	c.duration.Describe(ch)
+	c.latency.Describe(ch)
+}
+
+// Collect implement prometheus.Collector
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.popped.Collect(ch)
+	c.processed.Collect(ch)
+	c.retried.Collect(ch)
+	c.failed.Collect(ch)
+	c.duration.Collect(ch)
+	c.latency.Collect(ch)
+}
+
+var _ queue.MetricsCollector = (*Collector)(nil)
+var _ queue.DeliveryLatencyCollector = (*Collector)(nil)
@@ -0,0 +1,8 @@
+module github.com/jjonline/go-lib-backend/queue/queueprom
+
+go 1.16
+
+require (
+	github.com/jjonline/go-lib-backend/queue v0.0.0-20261015054417-4a0d825e9f95
+	github.com/prometheus/client_golang v1.11.1
+)
@@ -0,0 +1,36 @@
+/*
+ * @Time   : 2026/10/15 下午16:00
+ * @Email  : jjonline@jjonline.cn
+ */
+package queuetest
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock 可手动快进的测试时钟，配合 queue.Queue.SetClock 使用
+type Clock struct {
+	lock sync.Mutex
+	now  time.Time
+}
+
+// NewClock 实例化一个测试时钟
+// @param start 时钟初始时刻
+func NewClock(start time.Time) *Clock {
+	return &Clock{now: start}
+}
+
+// Now 时钟当前时刻
+func (c *Clock) Now() time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.now
+}
+
+// Advance 时钟快进指定时长
+func (c *Clock) Advance(d time.Duration) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.now = c.now.Add(d)
+}
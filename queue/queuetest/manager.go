@@ -0,0 +1,130 @@
+/*
+ * @Time   : 2026/10/15 下午21:00
+ * @Email  : jjonline@jjonline.cn
+ */
+package queuetest
+
+import (
+	"context"
+	"github.com/jjonline/go-lib-backend/queue"
+	"testing"
+	"time"
+)
+
+// *************************************************
+// 任务类单元测试工具
+// 1、基于线程安全的memory驱动（queue.Memory）与测试时钟，无需外部依赖即可注册、投递、执行任务类
+// 2、Drain 同步执行直至所有队列为空，延迟中的job通过快进测试时钟立即执行
+// 3、通过 Processed、Failed 获取执行成功、最终失败的job用于断言
+// *************************************************
+
+// drainPollInterval Drain 检查队列是否为空的间隔
+const drainPollInterval = 5 * time.Millisecond
+
+// drainClockStep 仅剩延迟中的job时每次快进测试时钟的时长
+const drainClockStep = time.Second
+
+// TestManager 任务类单元测试工具
+type TestManager struct {
+	*Harness
+	started bool
+}
+
+// NewTestManager 实例化任务类单元测试工具，注册任务类并在测试结束时自动优雅关闭队列
+func NewTestManager(tb testing.TB, tasks ...queue.TaskIFace) *TestManager {
+	tb.Helper()
+
+	m := &TestManager{Harness: NewHarness(tb, tasks...)}
+	if err := m.Queue.SetLooperInterval(time.Millisecond, 5*time.Millisecond); err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() {
+		if m.started {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			_ = m.Queue.ShutDown(ctx)
+		}
+	})
+
+	return m
+}
+
+// Dispatch 投递一个job，同 queue.Queue.Dispatch
+func (m *TestManager) Dispatch(task queue.TaskIFace, payload interface{}, opts ...queue.DispatchOption) error {
+	return m.Queue.Dispatch(task, payload, opts...)
+}
+
+// Drain 执行已投递的job直至所有已注册队列均为空且没有执行中的job，可多次调用
+// 1、首次调用时启动消费，之后保持运行直至测试结束
+// 2、仅剩延迟中（含等待重试）的job时快进测试时钟使其立即执行
+// 3、任务类始终执行失败且无限重试时不会结束，请通过ctx控制最长等待时长
+func (m *TestManager) Drain(ctx context.Context) error {
+	if !m.started {
+		if err := m.Queue.Start(); err != nil {
+			return err
+		}
+		m.started = true
+	}
+
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+	for {
+		busy, delayed, err := m.pending()
+		if err != nil {
+			return err
+		}
+		if !busy {
+			if !delayed {
+				return nil
+			}
+			m.Clock.Advance(drainClockStep)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// pending 检查是否仍有待执行、执行中的job，以及是否有延迟中的job
+func (m *TestManager) pending() (busy, delayed bool, err error) {
+	if m.Queue.Stats().InWorkingCount > 0 {
+		busy = true
+	}
+	for _, name := range m.Queue.Tasks() {
+		size, err := m.Queue.QueueSize(name)
+		if err != nil {
+			return false, false, err
+		}
+		if size.Pending > 0 || size.Reserved > 0 {
+			busy = true
+		}
+		if size.Delayed > 0 {
+			delayed = true
+		}
+	}
+	return busy, delayed, nil
+}
+
+// Processed 执行成功的job处理结果记录
+func (m *TestManager) Processed() []Outcome {
+	return m.outcomes(queue.JobProcessed)
+}
+
+// Failed 尝试次数耗尽最终失败的job处理结果记录
+func (m *TestManager) Failed() []Outcome {
+	return m.outcomes(queue.JobFailed)
+}
+
+// outcomes 指定处理结果的记录
+func (m *TestManager) outcomes(outcome queue.JobOutcome) []Outcome {
+	outcomes := make([]Outcome, 0)
+	for _, item := range m.Recorder.Outcomes() {
+		if item.Outcome == outcome {
+			outcomes = append(outcomes, item)
+		}
+	}
+	return outcomes
+}
@@ -0,0 +1,94 @@
+/*
+ * @Time   : 2026/10/15 下午16:00
+ * @Email  : jjonline@jjonline.cn
+ */
+package queuetest
+
+import (
+	"github.com/jjonline/go-lib-backend/queue"
+	"sync"
+	"time"
+)
+
+// Outcome 一次job处理结果记录
+type Outcome struct {
+	ID       string           // 任务ID
+	Name     string           // 队列名称
+	Attempts int64            // 本次为第几次尝试执行
+	Outcome  queue.JobOutcome // 处理结果
+	Delay    time.Duration    // 放回或再次投递的延迟时长
+	Err      error            // 导致该处理结果的错误
+}
+
+// Recorder job处理结果记录器：通过 queue.Queue.OnJobOutcome 记录manager对每个job做出的处理决定
+type Recorder struct {
+	lock     sync.Mutex
+	outcomes []Outcome
+	notify   chan struct{} // 有新记录时关闭并重建，用于等待新记录
+}
+
+// NewRecorder 实例化一个处理结果记录器
+func NewRecorder() *Recorder {
+	return &Recorder{notify: make(chan struct{})}
+}
+
+// Attach 将记录器设置为队列的job处理结果回调
+func (r *Recorder) Attach(service *queue.Queue) {
+	service.OnJobOutcome(r.Record)
+}
+
+// Record 记录一次job处理结果，签名同 queue.JobOutcomeHandler
+func (r *Recorder) Record(job queue.JobIFace, outcome queue.JobOutcome, detail queue.JobOutcomeDetail) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.outcomes = append(r.outcomes, Outcome{
+		ID:       job.Payload().ID,
+		Name:     job.GetName(),
+		Attempts: job.Attempts(),
+		Outcome:  outcome,
+		Delay:    detail.Delay,
+		Err:      detail.Err,
+	})
+	close(r.notify)
+	r.notify = make(chan struct{})
+}
+
+// Outcomes 全部处理结果记录副本，按记录先后排序
+func (r *Recorder) Outcomes() []Outcome {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	outcomes := make([]Outcome, len(r.outcomes))
+	copy(outcomes, r.outcomes)
+	return outcomes
+}
+
+// History 指定队列的处理结果记录，按记录先后排序
+func (r *Recorder) History(name string) []Outcome {
+	history := make([]Outcome, 0)
+	for _, outcome := range r.Outcomes() {
+		if outcome.Name == name {
+			history = append(history, outcome)
+		}
+	}
+	return history
+}
+
+// ReleaseDelays 指定队列每次放回重试的延迟时长，按记录先后排序
+func (r *Recorder) ReleaseDelays(name string) []time.Duration {
+	delays := make([]time.Duration, 0)
+	for _, outcome := range r.History(name) {
+		if outcome.Outcome == queue.JobReleased {
+			delays = append(delays, outcome.Delay)
+		}
+	}
+	return delays
+}
+
+// wait 返回当前记录数以及有新记录时关闭的chan
+func (r *Recorder) wait() (count int, notify <-chan struct{}) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return len(r.outcomes), r.notify
+}
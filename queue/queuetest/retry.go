@@ -0,0 +1,113 @@
+/*
+ * @Time   : 2026/10/15 下午16:00
+ * @Email  : jjonline@jjonline.cn
+ */
+package queuetest
+
+import (
+	"context"
+	"fmt"
+	"github.com/jjonline/go-lib-backend/queue"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+)
+
+// *************************************************
+// 队列重试行为测试辅助工具
+// 1、基于memory驱动以及可快进的测试时钟，job放回重试后自动快进重试间隔，无需真实等待
+// 2、通过 OnJobOutcome 记录manager在 Release、failJob 等决策处做出的处理决定，与生产环境执行同一流程
+// 3、断言job最终结果之前被放回重试的次数及每次的重试间隔
+// *************************************************
+
+// Harness 重试行为测试工具
+type Harness struct {
+	Queue    *queue.Queue // memory驱动的队列实例，可在 Run 之前做额外设置
+	Clock    *Clock       // 队列使用的测试时钟
+	Recorder *Recorder    // job处理结果记录器
+}
+
+// NewHarness 实例化重试行为测试工具，注册任务类并使用测试时钟
+func NewHarness(tb testing.TB, tasks ...queue.TaskIFace) *Harness {
+	tb.Helper()
+
+	h := &Harness{
+		Queue:    queue.New(queue.Memory, nil, zap.NewNop(), 1),
+		Clock:    NewClock(time.Now()),
+		Recorder: NewRecorder(),
+	}
+	if err := h.Queue.SetClock(h.Clock.Now); err != nil {
+		tb.Fatal(err)
+	}
+	if err := h.Queue.Bootstrap(tasks); err != nil {
+		tb.Fatal(err)
+	}
+	h.Recorder.Attach(h.Queue)
+
+	return h
+}
+
+// Run 启动队列消费直至指定队列出现最终处理结果（执行成功、最终失败、丢弃），返回该队列的处理结果记录
+// 每次job被放回重试或再次投递后，测试时钟自动快进对应的延迟时长
+// 请在 Dispatch 之后调用，队列中仅投递1个job时记录即为该job的完整重试历史
+func (h *Harness) Run(ctx context.Context, name string) (history []Outcome, err error) {
+	if err = h.Queue.Start(); err != nil {
+		return nil, err
+	}
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		_ = h.Queue.ShutDown(shutdownCtx)
+	}()
+
+	seen := 0
+	for {
+		count, notify := h.Recorder.wait()
+		for _, outcome := range h.Recorder.Outcomes()[seen:count] {
+			if outcome.Name != name {
+				continue
+			}
+			switch outcome.Outcome {
+			case queue.JobReleased, queue.JobRedelivered:
+				h.Clock.Advance(outcome.Delay)
+			default:
+				return h.Recorder.History(name), nil
+			}
+		}
+		seen = count
+
+		select {
+		case <-notify:
+		case <-ctx.Done():
+			return h.Recorder.History(name), ctx.Err()
+		}
+	}
+}
+
+// AssertRetries 断言处理结果记录：依次按给定延迟放回重试后以final结果结束
+// @param history Run 返回的处理结果记录
+// @param final   最终处理结果
+// @param delays  每次放回重试的延迟时长
+func AssertRetries(tb testing.TB, history []Outcome, final queue.JobOutcome, delays ...time.Duration) {
+	tb.Helper()
+
+	if err := checkRetries(history, final, delays); err != nil {
+		tb.Error(err)
+	}
+}
+
+// checkRetries 检查处理结果记录是否符合预期
+func checkRetries(history []Outcome, final queue.JobOutcome, delays []time.Duration) error {
+	if len(history) != len(delays)+1 {
+		return fmt.Errorf("queuetest: expect %d released and then %s, got %d outcomes: %v", len(delays), final, len(history), history)
+	}
+	for i, delay := range delays {
+		if history[i].Outcome != queue.JobReleased || history[i].Delay != delay {
+			return fmt.Errorf("queuetest: expect outcome #%d released with delay %s, got %s with delay %s", i+1, delay, history[i].Outcome, history[i].Delay)
+		}
+	}
+	if last := history[len(history)-1]; last.Outcome != final {
+		return fmt.Errorf("queuetest: expect final outcome %s, got %s", final, last.Outcome)
+	}
+	return nil
+}
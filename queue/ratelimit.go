@@ -0,0 +1,69 @@
+/*
+ * @Time   : 2026/10/16 上午10:00
+ * @Email  : jjonline@jjonline.cn
+ */
+package queue
+
+import (
+	"golang.org/x/time/rate"
+	"math"
+	"time"
+)
+
+// newTaskLimiter 按任务类设置的速率创建令牌桶限速器，未实现 RateLimitTask 或速率小于等于0时返回nil
+func newTaskLimiter(task TaskIFace) *rate.Limiter {
+	limitTask, ok := task.(RateLimitTask)
+	if !ok || limitTask.RatePerSecond() <= 0 {
+		return nil
+	}
+	perSecond := limitTask.RatePerSecond()
+	return rate.NewLimiter(rate.Limit(perSecond), int(math.Max(1, math.Ceil(perSecond))))
+}
+
+// taskLimiter 获取任务类的限速器，未限速时返回nil
+func (m *manager) taskLimiter(task TaskIFace) *rate.Limiter {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.limiters[task.Name()]
+}
+
+// isTaskRateLimited 检查任务类当前是否已无可用的取出配额
+func (m *manager) isTaskRateLimited(task TaskIFace) bool {
+	limiter := m.taskLimiter(task)
+	if limiter == nil {
+		return false
+	}
+	// 预留后立即以同一时刻取消，归还预留的配额
+	now := time.Now()
+	reservation := limiter.ReserveN(now, 1)
+	defer reservation.CancelAt(now)
+	return !reservation.OK() || reservation.DelayFrom(now) > 0
+}
+
+// acquireRateTokens 按任务类速率获取最多size个取出配额，返回获取到的数量以及归还未使用配额的方法
+func (m *manager) acquireRateTokens(task TaskIFace, size int64) (granted int64, giveBack func(unused int64)) {
+	limiter := m.taskLimiter(task)
+	if limiter == nil {
+		return size, func(int64) {}
+	}
+
+	now := time.Now()
+	reservations := make([]*rate.Reservation, 0, size)
+	for i := int64(0); i < size; i++ {
+		reservation := limiter.ReserveN(now, 1)
+		if !reservation.OK() || reservation.DelayFrom(now) > 0 {
+			reservation.CancelAt(now)
+			break
+		}
+		reservations = append(reservations, reservation)
+	}
+
+	// 多次归还时从尚未归还的配额末尾依次归还
+	kept := int64(len(reservations))
+	return kept, func(unused int64) {
+		for ; unused > 0 && kept > 0; unused-- {
+			kept--
+			reservations[kept].CancelAt(now)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+/*
+ * @Time   : 2026/10/16 下午21:00
+ * @Email  : jjonline@jjonline.cn
+ */
+package queue
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// recentEntry 最近执行成功的job记录
+type recentEntry struct {
+	id         string    // job ID
+	finishedAt time.Time // 执行成功时刻
+}
+
+// recentJobs 最近执行成功的job ID缓存：记录数量有上限，过期记录在每次读写时从最旧一端清理
+// 所有记录的有效时长相同，按写入顺序排列的链表头部即为最早过期的记录
+type recentJobs struct {
+	lock    sync.Mutex
+	ttl     time.Duration            // 记录有效时长
+	max     int                      // 最多记录数量，超出时淘汰最旧的记录
+	order   *list.List               // 按执行成功时刻排序的记录
+	entries map[string]*list.Element // job ID与记录的映射
+}
+
+// newRecentJobs 创建最近执行成功的job ID缓存
+func newRecentJobs(ttl time.Duration, max int) *recentJobs {
+	return &recentJobs{
+		ttl:     ttl,
+		max:     max,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// add 记录执行成功的job ID，同一ID重复记录时以最近一次为准
+func (r *recentJobs) add(id string, now time.Time) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.cleanLocked(now)
+	if element, ok := r.entries[id]; ok {
+		r.order.Remove(element)
+	}
+	r.entries[id] = r.order.PushBack(&recentEntry{id: id, finishedAt: now})
+	for r.order.Len() > r.max {
+		r.removeLocked(r.order.Front())
+	}
+}
+
+// contains 检查job ID是否在有效时长内执行成功过
+func (r *recentJobs) contains(id string, now time.Time) bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.cleanLocked(now)
+	_, ok := r.entries[id]
+	return ok
+}
+
+// cleanLocked 从最旧一端清理已过期的记录，调用方需持有锁
+func (r *recentJobs) cleanLocked(now time.Time) {
+	for element := r.order.Front(); element != nil; element = r.order.Front() {
+		if now.Sub(element.Value.(*recentEntry).finishedAt) < r.ttl {
+			return
+		}
+		r.removeLocked(element)
+	}
+}
+
+// removeLocked 删除一条记录，调用方需持有锁
+func (r *recentJobs) removeLocked(element *list.Element) {
+	r.order.Remove(element)
+	delete(r.entries, element.Value.(*recentEntry).id)
+}
+
+// setCompletedDedup 设置最近执行成功job的去重窗口，仅可在启动之前设置
+func (m *manager) setCompletedDedup(ttl time.Duration, max int) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.inStarted.isSet() {
+		return ErrQueueStarted
+	}
+	if ttl < 0 || (ttl > 0 && max <= 0) {
+		return fmt.Errorf("queue completed dedup window must not be less than 0 and max must be greater than 0")
+	}
+
+	if ttl == 0 {
+		m.recentCompleted = nil
+		return nil
+	}
+	m.recentCompleted = newRecentJobs(ttl, max)
+	return nil
+}
+
+// markCompleted 记录执行成功的job，未启用去重窗口时忽略
+func (m *manager) markCompleted(job JobIFace) {
+	if m.recentCompleted != nil {
+		m.recentCompleted.add(job.Payload().ID, time.Now())
+	}
+}
+
+// completedDedupWindow 已执行成功job的去重窗口，未启用时为0
+func (m *manager) completedDedupWindow() time.Duration {
+	if m.recentCompleted == nil {
+		return 0
+	}
+	return m.recentCompleted.ttl
+}
+
+// isRecentlyCompleted 检查job是否在去重窗口内已执行成功过，未启用去重窗口时始终返回false
+func (m *manager) isRecentlyCompleted(job JobIFace) bool {
+	return m.recentCompleted != nil && m.recentCompleted.contains(job.Payload().ID, time.Now())
+}
@@ -0,0 +1,75 @@
+/*
+ * @Time   : 2026/10/17 下午2:00
+ * @Email  : jjonline@jjonline.cn
+ */
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestExecutorStaleTaskNotCached 同名任务类重新注册后，旧任务类构建的执行方法不会覆盖新任务类的缓存
+func TestExecutorStaleTaskNotCached(t *testing.T) {
+	oldTask := &countTask{name: "executor_reregister"}
+	newTask := &countTask{name: "executor_reregister"}
+	q := newTestQueue(t, 1, oldTask)
+
+	_ = q.manager.executor(oldTask)
+	if err := q.Register(newTask); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	// 仍在执行旧任务类job的协程再次获取执行方法
+	_ = q.manager.executor(oldTask)(context.Background(), &RawBody{})
+
+	if err := q.manager.executor(newTask)(context.Background(), &RawBody{}); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if oldTask.count() != 1 || newTask.count() != 1 {
+		t.Fatalf("expected old 1 new 1, got old %d new %d", oldTask.count(), newTask.count())
+	}
+}
+
+// TestDeregisterStopsScheduledTask 注销周期任务后调度器不再投递其job
+func TestDeregisterStopsScheduledTask(t *testing.T) {
+	task := &scheduledCountTask{countTask{name: "deregister_scheduled"}}
+	q := newTestQueue(t, 1, task)
+	if err := q.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer q.ShutDown(context.Background())
+
+	waitFor(t, 3*time.Second, func() bool { return task.count() > 0 })
+	if err := q.Deregister(task.Name()); err != nil {
+		t.Fatalf("deregister: %v", err)
+	}
+
+	time.Sleep(2500 * time.Millisecond)
+	if size := q.Size(task); size != 0 {
+		t.Fatalf("expected no job scheduled after deregister, got %d", size)
+	}
+}
+
+// TestStrictStartNoTasks 严格启动模式下没有已注册任务类时启动返回error，启动后不可再修改
+func TestStrictStartNoTasks(t *testing.T) {
+	q := newTestQueue(t, 1)
+	if err := q.SetStrictStart(true); err != nil {
+		t.Fatalf("set strict start: %v", err)
+	}
+	if err := q.Start(); err != ErrNoTasksRegistered {
+		t.Fatalf("expected ErrNoTasksRegistered, got %v", err)
+	}
+
+	if err := q.SetStrictStart(false); err != nil {
+		t.Fatalf("set strict start: %v", err)
+	}
+	if err := q.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer q.ShutDown(context.Background())
+
+	if err := q.SetStrictStart(true); err != ErrQueueStarted {
+		t.Fatalf("expected ErrQueueStarted after start, got %v", err)
+	}
+}
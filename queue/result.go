@@ -0,0 +1,44 @@
+/*
+ * @Time   : 2026/10/15 下午23:00
+ * @Email  : jjonline@jjonline.cn
+ */
+package queue
+
+import (
+	"context"
+)
+
+// resultContextKey 执行上下文中job执行结果暂存器的key
+type resultContextKey struct{}
+
+// jobResult job执行结果暂存器：任务类执行协程写入，执行结果经chan回传后由worker读取
+type jobResult struct {
+	data []byte
+}
+
+// withResult 将job执行结果暂存器写入执行上下文
+func withResult(ctx context.Context, result *jobResult) context.Context {
+	return context.WithValue(ctx, resultContextKey{}, result)
+}
+
+// storeResultToContext 将任务类返回的执行结果写入执行上下文中的暂存器
+func storeResultToContext(ctx context.Context, data []byte) {
+	if result, ok := ctx.Value(resultContextKey{}).(*jobResult); ok {
+		result.data = data
+	}
+}
+
+// saveResult 保存job执行结果，未设置结果存储或结果为nil时不保存
+func (m *manager) saveResult(job JobIFace, result *jobResult) {
+	if m.resultStore == nil || result.data == nil {
+		return
+	}
+	if err := m.resultStore.Save(job.Payload().ID, result.data); err != nil {
+		m.workerLogger.Error(
+			"queue.job.result.save.failed",
+			field("queue", job.GetName()),
+			field("payload", job.Payload()),
+			field("error", err),
+		)
+	}
+}
@@ -0,0 +1,49 @@
+/*
+ * @Time   : 2026/10/17 下午4:00
+ * @Email  : jjonline@jjonline.cn
+ */
+package queue
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestRetryUsesFreshPopTime job执行失败放回重试后再次取出时，执行时长检查以本次取出时刻为准
+// 重试间隔大于超时时长：若仍以首次取出时刻计算，再次执行前即会误判为执行时间过长
+func TestRetryUsesFreshPopTime(t *testing.T) {
+	task := &countTask{
+		name:          "retry_fresh_pop_time",
+		maxTries:      2,
+		retryInterval: 3,
+		timeout:       time.Second,
+	}
+	var attempts int64
+	task.handler = func(ctx context.Context, job *RawBody) error {
+		if atomic.AddInt64(&attempts, 1) == 1 {
+			return errors.New("first attempt failed")
+		}
+		return nil
+	}
+
+	q := newTestQueue(t, 1, task)
+	var longRunning int64
+	q.OnLongRunning(func(job JobIFace, elapsed time.Duration) {
+		atomic.AddInt64(&longRunning, 1)
+	})
+	if err := q.Dispatch(task, "retry"); err != nil {
+		t.Fatalf("dispatch: %v", err)
+	}
+	if err := q.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer q.ShutDown(context.Background())
+
+	waitFor(t, 10*time.Second, func() bool { return task.count() == 2 })
+	if n := atomic.LoadInt64(&longRunning); n != 0 {
+		t.Fatalf("retried job reported as too long %d times", n)
+	}
+}
@@ -0,0 +1,118 @@
+/*
+ * @Time   : 2026/10/17 下午5:00
+ * @Email  : jjonline@jjonline.cn
+ */
+package queue
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestScaleDownWhileDispatching looper持续投递期间反复缩容至最小worker数，job全部执行且looper不会阻塞
+func TestScaleDownWhileDispatching(t *testing.T) {
+	task := &countTask{
+		name: "scale_stress",
+		handler: func(ctx context.Context, job *RawBody) error {
+			time.Sleep(time.Millisecond)
+			return nil
+		},
+	}
+	q := newTestQueue(t, 8, task)
+	const jobs = 500
+	for i := 0; i < jobs; i++ {
+		if err := q.Dispatch(task, i); err != nil {
+			t.Fatalf("dispatch: %v", err)
+		}
+	}
+	if err := q.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+
+	stop := make(chan struct{})
+	scaled := make(chan struct{})
+	go func() {
+		defer close(scaled)
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			concurrent := int64(1)
+			if i%2 == 1 {
+				concurrent = 8
+			}
+			if err := q.Scale(concurrent); err != nil {
+				t.Errorf("scale: %v", err)
+				return
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}()
+
+	waitFor(t, 20*time.Second, func() bool { return task.count() == jobs })
+	close(stop)
+	<-scaled
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := q.ShutDown(ctx); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+}
+
+// TestReleaseUndispatchedKeepsAttempts 未能投递给worker的job放回队列时不消耗尝试次数
+func TestReleaseUndispatchedKeepsAttempts(t *testing.T) {
+	task := &countTask{name: "release_undispatched"}
+	q := newTestQueue(t, 1, task)
+	if err := q.Dispatch(task, "undispatched"); err != nil {
+		t.Fatalf("dispatch: %v", err)
+	}
+
+	job, ok := q.queue.Pop(task.Name())
+	if !ok {
+		t.Fatal("pop failed")
+	}
+	q.manager.releaseUndispatched([]JobIFace{job})
+
+	job, ok = q.queue.Pop(task.Name())
+	if !ok {
+		t.Fatal("pop after release failed")
+	}
+	if job.Attempts() != 1 {
+		t.Fatalf("expected attempts 1 after undispatched release, got %d", job.Attempts())
+	}
+}
+
+// TestIdleWorkerCount 空闲worker计数随job执行与扩缩容同步变化
+func TestIdleWorkerCount(t *testing.T) {
+	release := make(chan struct{})
+	task := &countTask{
+		name: "idle_count",
+		handler: func(ctx context.Context, job *RawBody) error {
+			<-release
+			return nil
+		},
+	}
+	q := newTestQueue(t, 3, task)
+	if err := q.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer q.ShutDown(context.Background())
+
+	idle := func() int64 { return atomic.LoadInt64(&q.manager.idleWorkers) }
+	waitFor(t, 5*time.Second, func() bool { return idle() == 3 })
+	if err := q.Dispatch(task, "block"); err != nil {
+		t.Fatalf("dispatch: %v", err)
+	}
+	waitFor(t, 5*time.Second, func() bool { return idle() == 2 })
+	close(release)
+	waitFor(t, 5*time.Second, func() bool { return idle() == 3 })
+	if err := q.Scale(1); err != nil {
+		t.Fatalf("scale: %v", err)
+	}
+	waitFor(t, 5*time.Second, func() bool { return idle() == 1 })
+}
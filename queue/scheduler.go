@@ -0,0 +1,144 @@
+/*
+ * @Time   : 2026/10/15 下午20:00
+ * @Email  : jjonline@jjonline.cn
+ */
+package queue
+
+import (
+	"fmt"
+	"github.com/robfig/cron/v3"
+	"time"
+)
+
+// *************************************************
+// 周期任务调度器
+// 1、实现了 ScheduledTask 的任务类按其cron表达式周期性的自动投递job，无需外部cron触发
+// 2、调度器协程随消费者启动，优雅关闭时先于looper退出，处于关闭中时停止投递
+// 3、每个消费者进程均会按表达式投递，多实例部署时仅需在一个实例上注册周期任务
+// 4、启动后注册、注销、重新载入任务类时同步调度条目，已注销的周期任务不再投递
+// *************************************************
+
+// schedulerIdleInterval 没有周期任务时调度器的空闲等待间隔，注册周期任务时会被立即唤醒
+const schedulerIdleInterval = time.Hour
+
+// scheduledEntry 周期任务调度条目
+type scheduledEntry struct {
+	task     TaskIFace     // 任务类
+	schedule cron.Schedule // 解析后的cron表达式
+	next     time.Time     // 下次投递时刻
+}
+
+// parseSchedule 解析任务类的cron表达式：标准5段式（分 时 日 月 周）以及 @every 1m 等描述符
+func parseSchedule(task ScheduledTask) (cron.Schedule, error) {
+	schedule, err := cron.ParseStandard(task.Schedule())
+	if err != nil {
+		return nil, fmt.Errorf("queue scheduled task schedule %s invalid: %s", task.Schedule(), err.Error())
+	}
+	return schedule, nil
+}
+
+// scheduledEntries 同步已注册的周期任务调度条目：仍注册中的同一任务类沿用原下次投递时刻，新注册的任务类自now起计算
+// @param prev 同步前的调度条目，首次同步传nil
+func (m *manager) scheduledEntries(prev []*scheduledEntry, now time.Time) []*scheduledEntry {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	prevEntries := make(map[string]*scheduledEntry, len(prev))
+	for _, entry := range prev {
+		prevEntries[entry.task.Name()] = entry
+	}
+
+	entries := make([]*scheduledEntry, 0)
+	for name, task := range m.tasks {
+		scheduledTask, ok := task.(ScheduledTask)
+		if !ok {
+			continue
+		}
+		if entry, exist := prevEntries[name]; exist && sameTask(entry.task, task) {
+			entries = append(entries, entry)
+			continue
+		}
+		// 注册时已校验表达式
+		schedule, err := parseSchedule(scheduledTask)
+		if err != nil {
+			continue
+		}
+		entries = append(entries, &scheduledEntry{task: task, schedule: schedule, next: schedule.Next(now)})
+	}
+	return entries
+}
+
+// notifyScheduleChanged 通知调度器任务类集合已变化需同步调度条目，调用方可持有锁
+func (m *manager) notifyScheduleChanged() {
+	select {
+	case m.scheduleChanged <- struct{}{}:
+	default:
+	}
+}
+
+// startScheduler 启动周期任务调度器：按各任务类的cron表达式到期投递job
+func (m *manager) startScheduler() {
+	defer m.producerWg.Done()
+
+	entries := m.scheduledEntries(nil, time.Now())
+	timer := time.NewTimer(time.Until(nextScheduled(entries)))
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-m.producerDone:
+			m.looperLogger.Info("shutdown, queue scheduler exited")
+			return
+		case <-m.scheduleChanged:
+			entries = m.scheduledEntries(entries, time.Now())
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+			timer.Reset(time.Until(nextScheduled(entries)))
+		case now := <-timer.C:
+			for _, entry := range entries {
+				if entry.next.After(now) {
+					continue
+				}
+				if !m.shuttingDown() {
+					m.enqueueScheduled(entry.task, entry.next)
+				}
+				entry.next = entry.schedule.Next(now)
+			}
+			timer.Reset(time.Until(nextScheduled(entries)))
+		}
+	}
+}
+
+// enqueueScheduled 投递一次周期任务job，job参数为本次计划投递时刻的秒级时间戳
+func (m *manager) enqueueScheduled(task TaskIFace, at time.Time) {
+	// 周期任务job的ID不使用 SetIDGenerator 设置的生成方法
+	basic := *m.basic
+	basic.idGenerator = nil
+	payload, err := basic.marshalPayload(task, at.Unix(), nil, at, 0)
+	if err == nil {
+		err = m.queue.Push(task.Name(), payload)
+	}
+	if err != nil {
+		m.looperLogger.Error("queue.scheduled.enqueue.failed", field("queue", task.Name()), field("error", err))
+		return
+	}
+	m.looperLogger.Debug("queue.scheduled.enqueued", field("queue", task.Name()), field("at", at))
+}
+
+// nextScheduled 所有周期任务中最近的下次投递时刻，没有周期任务时为空闲检查间隔之后
+func nextScheduled(entries []*scheduledEntry) time.Time {
+	if len(entries) == 0 {
+		return time.Now().Add(schedulerIdleInterval)
+	}
+	next := entries[0].next
+	for _, entry := range entries[1:] {
+		if entry.next.Before(next) {
+			next = entry.next
+		}
+	}
+	return next
+}
@@ -0,0 +1,61 @@
+/*
+ * @Time   : 2026/10/17 下午3:00
+ * @Email  : jjonline@jjonline.cn
+ */
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestSchedulerUsesQueueSettingsForTaskRegisteredAfterStart 启动后注册的周期任务同样被调度，且job使用Queue设置的编码器
+func TestSchedulerUsesQueueSettingsForTaskRegisteredAfterStart(t *testing.T) {
+	q := newTestQueue(t, 1, &countTask{name: "scheduler_placeholder"})
+	q.SetCodec(GzipCodec{}, 0)
+	if err := q.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer q.ShutDown(context.Background())
+	// 暂停取出，便于查看调度器投递的job
+	q.Pause()
+
+	task := &scheduledCountTask{countTask{name: "scheduler_after_start"}}
+	if err := q.Register(task); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+
+	waitFor(t, 3*time.Second, func() bool { return q.Size(task) > 0 })
+	payloads, err := q.Peek(task.Name(), 1)
+	if err != nil || len(payloads) != 1 {
+		t.Fatalf("peek: %v %v", payloads, err)
+	}
+	if payloads[0].Encoding != "gzip" {
+		t.Fatalf("expected scheduled job encoded by queue codec, got %q", payloads[0].Encoding)
+	}
+}
+
+// TestReloadRefreshesScheduler 重新载入任务类后被移除的周期任务不再投递，新增的周期任务开始投递
+func TestReloadRefreshesScheduler(t *testing.T) {
+	removed := &scheduledCountTask{countTask{name: "reload_removed_scheduled"}}
+	q := newTestQueue(t, 1, removed)
+	if err := q.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer q.ShutDown(context.Background())
+	waitFor(t, 3*time.Second, func() bool { return removed.count() > 0 })
+
+	added := &scheduledCountTask{countTask{name: "reload_added_scheduled"}}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	if err := q.Reload(ctx, []TaskIFace{added}); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+
+	waitFor(t, 3*time.Second, func() bool { return added.count() > 0 })
+	time.Sleep(1500 * time.Millisecond)
+	if size := q.Size(removed); size != 0 {
+		t.Fatalf("expected removed scheduled task not enqueued after reload, got %d", size)
+	}
+}
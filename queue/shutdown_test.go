@@ -0,0 +1,88 @@
+/*
+ * @Time   : 2026/10/17 上午10:00
+ * @Email  : jjonline@jjonline.cn
+ */
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.uber.org/goleak"
+)
+
+// TestShutDownNoLeak 优雅关闭返回nil后队列启动的协程均已退出
+func TestShutDownNoLeak(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	task := &countTask{name: "shutdown_no_leak"}
+	scheduled := &scheduledCountTask{countTask{name: "shutdown_no_leak_scheduled"}}
+	q := newTestQueue(t, 4, task, scheduled)
+	for i := 0; i < 20; i++ {
+		if err := q.Dispatch(task, i); err != nil {
+			t.Fatalf("dispatch: %v", err)
+		}
+	}
+	if err := q.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	waitFor(t, 5*time.Second, func() bool { return task.count() == 20 })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := q.ShutDown(ctx); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+}
+
+// TestShutDownExpiredContextStopsLoopers 等待超时返回error时looper、worker也已收到退出信号，不再遗留协程
+func TestShutDownExpiredContextStopsLoopers(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	task := &countTask{name: "shutdown_expired"}
+	scheduled := &scheduledCountTask{countTask{name: "shutdown_expired_scheduled"}}
+	q := newTestQueue(t, 2, task, scheduled)
+	if err := q.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_ = q.ShutDown(ctx)
+
+	// 关闭后投递的job不再被取出执行
+	_ = q.Dispatch(task, "after shutdown")
+	time.Sleep(200 * time.Millisecond)
+	if task.count() != 0 {
+		t.Fatalf("job executed after shutdown: %d", task.count())
+	}
+}
+
+// TestDispatchRejectedWhileShuttingDown 优雅关闭中（或已关闭）投递任务返回 ErrQueueClosed
+func TestDispatchRejectedWhileShuttingDown(t *testing.T) {
+	task := &countTask{name: "dispatch_closed"}
+	q := newTestQueue(t, 1, task)
+	if err := q.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	if err := q.ShutDown(context.Background()); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+
+	if !q.IsShuttingDown() {
+		t.Fatalf("expected queue shutting down")
+	}
+	if err := q.Dispatch(task, "x"); err != ErrQueueClosed {
+		t.Fatalf("expected ErrQueueClosed from Dispatch, got %v", err)
+	}
+	if err := q.Delay(task, "x", time.Second); err != ErrQueueClosed {
+		t.Fatalf("expected ErrQueueClosed from Delay, got %v", err)
+	}
+	if err := q.DispatchAfter(task.Name(), time.Second, "x"); err != ErrQueueClosed {
+		t.Fatalf("expected ErrQueueClosed from DispatchAfter, got %v", err)
+	}
+	if size := q.queue.Size(task.Name()); size != 0 {
+		t.Fatalf("expected no job enqueued, got %d", size)
+	}
+}
@@ -0,0 +1,63 @@
+/*
+ * @Time   : 2026/10/17 下午7:00
+ * @Email  : jjonline@jjonline.cn
+ */
+package queue
+
+import (
+	"context"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// TestRunUntilSignalGracefulOnSIGTERM 启动后收到SIGTERM时优雅停止并返回
+func TestRunUntilSignalGracefulOnSIGTERM(t *testing.T) {
+	q := newTestQueue(t, 1, &countTask{name: "run_until_signal"})
+
+	result := make(chan error, 1)
+	go func() {
+		result <- q.RunUntilSignal(3 * time.Second)
+	}()
+	// 启动前已监听信号，启动后发送的信号必然被捕获
+	waitFor(t, 3*time.Second, q.manager.inStarted.isSet)
+	if err := syscall.Kill(os.Getpid(), syscall.SIGTERM); err != nil {
+		t.Fatalf("kill: %v", err)
+	}
+
+	select {
+	case err := <-result:
+		if err != nil {
+			t.Fatalf("run until signal: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("run until signal did not return after SIGTERM")
+	}
+	if !q.IsShuttingDown() {
+		t.Fatal("queue should be shut down")
+	}
+}
+
+// TestRunUntilSignalReturnsOnShutDown 由 ShutDown 关闭队列时不再等待信号
+func TestRunUntilSignalReturnsOnShutDown(t *testing.T) {
+	q := newTestQueue(t, 1, &countTask{name: "run_until_shutdown"})
+
+	result := make(chan error, 1)
+	go func() {
+		result <- q.RunUntilSignal(3 * time.Second)
+	}()
+	waitFor(t, 3*time.Second, q.manager.inStarted.isSet)
+	if err := q.ShutDown(context.Background()); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+
+	select {
+	case err := <-result:
+		if err != nil {
+			t.Fatalf("run until signal: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("run until signal did not return after shutdown")
+	}
+}
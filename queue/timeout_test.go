@@ -0,0 +1,124 @@
+/*
+ * @Time   : 2026/10/18 上午11:00
+ * @Email  : jjonline@jjonline.cn
+ */
+package queue
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// panicOnTimeoutTask 超时回调panic的任务类
+type panicOnTimeoutTask struct {
+	countTask
+	timeouts int64
+}
+
+func (task *panicOnTimeoutTask) OnTimeout(payload Payload) {
+	atomic.AddInt64(&task.timeouts, 1)
+	panic("on timeout panic")
+}
+
+// TestOnTimeoutPanicRecovered 超时回调panic时worker不崩溃，后续job继续执行
+func TestOnTimeoutPanicRecovered(t *testing.T) {
+	task := &panicOnTimeoutTask{countTask: countTask{
+		name:    "on_timeout_panic",
+		timeout: time.Second,
+		handler: func(ctx context.Context, job *RawBody) error {
+			if job.String() == "slow" {
+				<-ctx.Done()
+				return ctx.Err()
+			}
+			return nil
+		},
+	}}
+	q := newTestQueue(t, 1, task)
+	_ = q.Dispatch(task, "slow")
+	_ = q.Dispatch(task, "fast")
+	if err := q.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer q.ShutDown(context.Background())
+
+	waitFor(t, 5*time.Second, func() bool { return task.count() == 2 })
+	if atomic.LoadInt64(&task.timeouts) != 1 {
+		t.Fatalf("expected OnTimeout called once, got %d", task.timeouts)
+	}
+}
+
+// noTimeoutTask 未设置超时时长的任务类
+type noTimeoutTask struct {
+	countTask
+}
+
+func (task *noTimeoutTask) Timeout() time.Duration {
+	return 0
+}
+
+// TestSetMaxExecuteDuration 未设置超时时长的job按设置的最大执行时长记录超时时长
+func TestSetMaxExecuteDuration(t *testing.T) {
+	task := &noTimeoutTask{countTask{name: "max_execute_duration"}}
+	q := newTestQueue(t, 1, task)
+	if err := q.SetMaxExecuteDuration(0); err == nil {
+		t.Fatalf("expected error for non-positive max execute duration")
+	}
+	if err := q.SetMaxExecuteDuration(2 * time.Hour); err != nil {
+		t.Fatalf("set max execute duration: %v", err)
+	}
+	if err := q.Dispatch(task, "long"); err != nil {
+		t.Fatalf("dispatch: %v", err)
+	}
+	payloads, err := q.Peek(task.Name(), 1)
+	if err != nil || len(payloads) != 1 {
+		t.Fatalf("peek: %v %v", payloads, err)
+	}
+	if payloads[0].Timeout != int64((2 * time.Hour).Seconds()) {
+		t.Fatalf("expected payload timeout 7200, got %d", payloads[0].Timeout)
+	}
+	if q.manager.defaultTimeout != 2*time.Hour {
+		t.Fatalf("expected default job timeout 2h, got %s", q.manager.defaultTimeout)
+	}
+
+	if err = q.SetJobTimeout(time.Minute, 10*time.Minute); err != nil {
+		t.Fatalf("set job timeout: %v", err)
+	}
+	if err = q.SetMaxExecuteDuration(time.Hour); err == nil {
+		t.Fatalf("expected error for max execute duration above max job timeout")
+	}
+}
+
+// TestOnLongRunningPanicRecoveredAndNotifiedCleared 执行时长超限回调panic被捕获，job离开后清理告警标记
+func TestOnLongRunningPanicRecoveredAndNotifiedCleared(t *testing.T) {
+	task := &countTask{
+		name:    "long_running_panic",
+		timeout: time.Second,
+		handler: func(ctx context.Context, job *RawBody) error {
+			time.Sleep(1100 * time.Millisecond)
+			return nil
+		},
+	}
+	q := newTestQueue(t, 1, task)
+	var called int64
+	q.OnLongRunning(func(job JobIFace, elapsed time.Duration) {
+		atomic.AddInt64(&called, 1)
+		panic("long running callback panic")
+	})
+	if err := q.Dispatch(task, "slow"); err != nil {
+		t.Fatalf("dispatch: %v", err)
+	}
+	if err := q.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer q.ShutDown(context.Background())
+
+	waitFor(t, 5*time.Second, func() bool { return atomic.LoadInt64(&called) == 1 && q.manager.inWorkingCount() == 0 })
+	q.manager.lock.Lock()
+	notified := len(q.manager.longRunningNotified)
+	q.manager.lock.Unlock()
+	if notified != 0 {
+		t.Fatalf("expected long running notified entries cleared, got %d", notified)
+	}
+}
@@ -0,0 +1,76 @@
+/*
+ * @Time   : 2026/10/15 下午3:30
+ * @Email  : jjonline@jjonline.cn
+ */
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Bind 将任务参数Unmarshal为指定类型，免去任务类中先声明变量再 Unmarshal 的样板代码
+//   - 与 RawBody.Unmarshal 一致使用json解码，通常传入 job.Bytes()
+//   - 解码成功返回填充后的值，失败时返回T的零值与error
+func Bind[T any](body []byte) (T, error) {
+	var result T
+	if err := json.Unmarshal(body, &result); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// TypedHandler 泛型任务类执行契约：执行时接收已解码为T类型的任务参数，执行成功返回nil，执行失败返回error
+type TypedHandler[T any] interface {
+	Handle(ctx context.Context, payload T) error
+}
+
+// TypedTask 泛型任务类基础struct：任务类嵌入后只需实现 TypedHandler 的 Handle 方法，无需实现 Execute
+//   - 执行时先将任务参数解码为T类型再调用任务类的 Handle，中间件、超时、重试等执行流程与实现 Execute 的任务类一致
+//   - 参数无法解码为T类型时job不再重试，直接按最终失败处理，最终失败的error包装了 ErrPayloadInvalid
+//   - 嵌入后未实现 Handle 方法的任务类注册时返回error
+//
+// 与 DefaultTaskSetting 等一同嵌入使用，例如：
+//
+//	type MailTask struct {
+//		queue.DefaultTaskSetting
+//		queue.TypedTask[MailParam]
+//	}
+//
+//	func (task *MailTask) Handle(ctx context.Context, param MailParam) error
+type TypedTask[T any] struct{}
+
+// Execute implement TaskIFace
+// 队列执行job时不会调用该方法；嵌入后单独调用时拿不到外层任务类的 Handle，直接返回error
+func (task *TypedTask[T]) Execute(ctx context.Context, job *RawBody) error {
+	return fmt.Errorf("queue typed task must be executed by queue, call Handle directly instead")
+}
+
+// executeTyped implement typedExecutor：解码任务参数后调用外层任务类的 Handle
+func (task *TypedTask[T]) executeTyped(ctx context.Context, job *RawBody, outer TaskIFace) error {
+	handler, ok := outer.(TypedHandler[T])
+	if !ok {
+		return fmt.Errorf("queue typed task %s must implement Handle(ctx, %T) error", outer.Name(), *new(T))
+	}
+
+	payload, err := Bind[T](job.Bytes())
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrPayloadInvalid, err.Error())
+	}
+	return handler.Handle(ctx, payload)
+}
+
+// checkTyped implement typedExecutor：检查外层任务类是否实现了对应类型的 Handle
+func (task *TypedTask[T]) checkTyped(outer TaskIFace) error {
+	if _, ok := outer.(TypedHandler[T]); !ok {
+		return fmt.Errorf("queue typed task %s must implement Handle(ctx, %T) error", outer.Name(), *new(T))
+	}
+	return nil
+}
+
+// typedExecutor 嵌入了 TypedTask 的任务类，非泛型以便通过类型断言识别
+type typedExecutor interface {
+	executeTyped(ctx context.Context, job *RawBody, outer TaskIFace) error
+	checkTyped(outer TaskIFace) error
+}
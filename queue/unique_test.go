@@ -0,0 +1,63 @@
+/*
+ * @Time   : 2026/10/17 下午6:00
+ * @Email  : jjonline@jjonline.cn
+ */
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// TestDispatchUniqueRunsOnce 唯一窗口期内同一唯一键投递两次仅执行一次
+func TestDispatchUniqueRunsOnce(t *testing.T) {
+	task := &countTask{name: "unique_once"}
+	q := newTestQueue(t, 2, task)
+
+	if err := q.Dispatch(task, "first", WithUniqueFor("order-1", time.Minute)); err != nil {
+		t.Fatalf("dispatch: %v", err)
+	}
+	if err := q.Dispatch(task, "second", WithUniqueFor("order-1", time.Minute)); !errors.Is(err, ErrDuplicateJob) {
+		t.Fatalf("expected ErrDuplicateJob, got %v", err)
+	}
+	if err := q.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer q.ShutDown(context.Background())
+
+	waitFor(t, 3*time.Second, func() bool { return task.count() == 1 })
+	time.Sleep(200 * time.Millisecond)
+	if task.count() != 1 {
+		t.Fatalf("expected unique job executed once, got %d", task.count())
+	}
+}
+
+// TestMemoryPushUniqueSweepsExpiredKeys memory驱动按阈值清理已过期的唯一键，窗口期过后可再次投递
+func TestMemoryPushUniqueSweepsExpiredKeys(t *testing.T) {
+	now := time.Now()
+	m := &memoryQueue{clock: func() time.Time { return now }}
+	payload, _ := m.marshalPayload(&countTask{name: "unique_sweep"}, "body", nil, now, 0)
+
+	for i := 0; i < memoryUniqueSweepMin; i++ {
+		if _, err := m.PushUnique("unique_sweep", fmt.Sprint(i), payload, time.Second); err != nil {
+			t.Fatalf("push unique: %v", err)
+		}
+	}
+	now = now.Add(2 * time.Second)
+	pushed, err := m.PushUnique("unique_sweep", "0", payload, time.Second)
+	if err != nil || !pushed {
+		t.Fatalf("expected push after window expired, pushed %v err %v", pushed, err)
+	}
+	// 唯一键数量达到清理阈值时清理已过期的唯一键
+	for i := 0; i < memoryUniqueSweepMin; i++ {
+		if _, err = m.PushUnique("unique_sweep", fmt.Sprint("new-", i), payload, time.Second); err != nil {
+			t.Fatalf("push unique: %v", err)
+		}
+	}
+	if len(m.unique) != memoryUniqueSweepMin+1 {
+		t.Fatalf("expected expired unique keys swept, got %d", len(m.unique))
+	}
+}
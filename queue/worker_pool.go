@@ -0,0 +1,70 @@
+/*
+ * @Time   : 2026/10/16 下午17:00
+ * @Email  : jjonline@jjonline.cn
+ */
+package queue
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// dedicatedPool 指定队列专用的worker池，与普通worker隔离
+type dedicatedPool struct {
+	name    string          // 队列名称
+	workers int64           // 专用worker数量
+	channel chan []JobIFace // 专用通道chan，仅该池的worker消费
+	idle    int64           // 空闲的专用worker数量，原子读写
+}
+
+// setDedicatedWorkers 为指定队列设置专用worker池，重复设置同一队列时覆盖，仅可在启动之前设置
+func (m *manager) setDedicatedWorkers(name string, workers int64) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.inStarted.isSet() {
+		return ErrQueueStarted
+	}
+	if name == "" || workers <= 0 {
+		return fmt.Errorf("queue dedicated workers must be greater than 0 and queue name must not be empty")
+	}
+
+	m.dedicatedPools[name] = &dedicatedPool{
+		name:    name,
+		workers: workers,
+		channel: make(chan []JobIFace),
+	}
+	return nil
+}
+
+// spawnDedicatedWorkersLocked 启动所有专用worker池的worker，调用方需持有锁
+// 专用worker以新的workerID启动，不支持缩容，无退出信号chan
+func (m *manager) spawnDedicatedWorkersLocked() {
+	for _, pool := range m.dedicatedPools {
+		for i := int64(0); i < pool.workers; i++ {
+			workerID := m.nextWorkerID
+			m.nextWorkerID++
+
+			m.dedicatedWorkers[workerID] = pool
+			atomic.AddInt64(&m.liveWorkers, 1)
+			go m.startWorker(workerID, nil)
+		}
+	}
+}
+
+// dedicatedPoolOfWorker 获取专用worker所属的worker池，普通worker返回nil
+func (m *manager) dedicatedPoolOfWorker(workerID int64) *dedicatedPool {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.dedicatedWorkers[workerID]
+}
+
+// closeDedicatedChannels 关闭所有专用通道，专用worker消费完毕后随之退出
+func (m *manager) closeDedicatedChannels() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	for _, pool := range m.dedicatedPools {
+		close(pool.channel)
+	}
+}